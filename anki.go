@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"strings"
+
+	"wordef/dict"
+)
+
+// ankiHeader tells Anki how to import the file, see
+// https://docs.ankiweb.net/importing/text-files.html#file-headers
+const ankiHeader = "#separator:tab\n#html:true\n"
+
+var ankiFieldReplacer = strings.NewReplacer("\t", " ", "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+
+// ankiField escapes text for an HTML field of a tab separated Anki file.
+func ankiField(text string) string {
+	return ankiFieldReplacer.Replace(html.EscapeString(text))
+}
+
+// ankiBack returns the back of a card, the phonetic spelling followed by
+// the definitions grouped by part of speech.
+func ankiBack(wordInfo dict.WordInfo) string {
+	var b strings.Builder
+
+	primary, _ := phoneticSpellings(wordInfo)
+
+	if primary != "" {
+		fmt.Fprintf(&b, "<i>%s</i><br>", ankiField(primary))
+	}
+
+	for _, m := range wordInfo.Meanings {
+		if len(m.Definitions) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "<b>%s</b><ol>", ankiField(m.PartOfSpeech))
+
+		for _, d := range m.Definitions {
+			fmt.Fprintf(&b, "<li>%s", ankiField(d.Definition))
+
+			if d.Example != "" {
+				fmt.Fprintf(&b, "<br><i>\"%s\"</i>", ankiField(d.Example))
+			}
+
+			b.WriteString("</li>")
+		}
+
+		b.WriteString("</ol>")
+	}
+
+	return b.String()
+}
+
+func renderAnkiCard(w io.Writer, wordInfo dict.WordInfo) error {
+	_, err := fmt.Fprintf(w, "%s\t%s\n", ankiField(wordInfo.Word), ankiBack(wordInfo))
+
+	return err
+}
+
+// handleAnkiCommand prints one Anki card per word, words that cannot be
+// found are reported on stderr.
+func handleAnkiCommand(words []string, cacheDir string, opts searchOptions) error {
+	fmt.Print(ankiHeader)
+
+	return forEachWordInfo(words, cacheDir, opts, func(wordInfo dict.WordInfo) error {
+		return renderAnkiCard(os.Stdout, wordInfo)
+	})
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"runtime"
+	"strings"
+
+	"wordef/dict"
+)
+
+func getAudioPlayer() ([]string, error) {
+	player := os.Getenv("WORDEF_AUDIO_PLAYER")
+
+	if player != "" {
+		return strings.Fields(player), nil
+	}
+
+	var candidates [][]string
+
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = [][]string{{"afplay"}}
+	case "windows":
+		return []string{"cmd", "/c", "start", "/wait", ""}, nil
+	default:
+		// The pronunciations are mp3 files, which paplay and aplay only play
+		// as noise, so they are the last resort.
+		candidates = [][]string{
+			{"mpg123", "-q"},
+			{"ffplay", "-nodisp", "-autoexit", "-loglevel", "quiet"},
+			{"mpv", "--no-video", "--really-quiet"},
+			{"paplay"},
+			{"aplay"},
+		}
+	}
+
+	for _, candidate := range candidates {
+		_, err := exec.LookPath(candidate[0])
+
+		if err == nil {
+			return candidate, nil
+		}
+	}
+
+	return nil, errors.New("No audio player found, set WORDEF_AUDIO_PLAYER to a command that plays audio files")
+}
+
+func downloadAudio(url string) (fileName string, err error) {
+	resp, err := dict.HTTPClient.Get(url)
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to download audio: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Failed to download audio: server returned status %d", resp.StatusCode)
+	}
+
+	file, err := os.CreateTemp("", "wordef-*"+path.Ext(url))
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to create temporary audio file: %w", err)
+	}
+
+	defer file.Close()
+
+	_, err = io.Copy(file, resp.Body)
+
+	if err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("Failed to save audio: %w", err)
+	}
+
+	return file.Name(), nil
+}
+
+func playAudio(url string) error {
+	player, err := getAudioPlayer()
+
+	if err != nil {
+		return err
+	}
+
+	fileName, err := downloadAudio(url)
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(fileName)
+
+	args := append(player[1:], fileName)
+	cmd := exec.Command(player[0], args...)
+	cmd.Stderr = os.Stderr
+
+	err = cmd.Run()
+
+	if err != nil {
+		return fmt.Errorf("Failed to play audio with %s: %w", player[0], err)
+	}
+
+	return nil
+}
+
+func playFirstAudio(wordInfo dict.WordInfo) error {
+	for _, p := range wordInfo.Phonetics {
+		if p.Audio != "" {
+			return playAudio(p.Audio)
+		}
+	}
+
+	return fmt.Errorf("No audio pronunciations available for '%s'", wordInfo.Word)
+}
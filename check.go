@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"wordef/dict"
+)
+
+// unknownWordError is returned by --check for a word without definitions.
+type unknownWordError struct {
+	word string
+}
+
+func (e *unknownWordError) Error() string {
+	return fmt.Sprintf("unknown word '%s'", e.word)
+}
+
+func (e *unknownWordError) Is(target error) bool {
+	return target == dict.ErrWordNotFound
+}
+
+// handleCheckCommand reports which words have no definitions, it prints
+// "ok" when a single word checked out.
+func handleCheckCommand(words []string, cacheDir string, opts searchOptions) error {
+	var errs []error
+
+	for _, r := range lookupWords(words, cacheDir, opts) {
+		err := r.Err
+
+		if errors.Is(err, dict.ErrWordNotFound) {
+			err = &unknownWordError{word: r.Word}
+		}
+
+		if err == nil {
+			continue
+		}
+
+		if len(words) == 1 {
+			return err
+		}
+
+		fmt.Fprintln(os.Stderr, err)
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return &batchError{errs: errs, total: len(words)}
+	}
+
+	if len(words) == 1 {
+		fmt.Println("ok")
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	ansiBold  = "\033[1m"
+	ansiReset = "\033[0m"
+)
+
+// useColor resolves a --color value, auto only colors output written to a
+// terminal and respects the NO_COLOR convention.
+func useColor(mode string) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		return isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == "", nil
+	}
+
+	return false, fmt.Errorf("Invalid --color value %q, expected auto, always or never", mode)
+}
+
+func bold(s string, color bool) string {
+	if !color {
+		return s
+	}
+
+	return ansiBold + s + ansiReset
+}
+
+// hyperlink wraps url in an OSC 8 escape sequence so terminals that support
+// it make the url clickable, other terminals print the url unchanged.
+func hyperlink(url string, color bool) string {
+	if !color || !isTerminal(os.Stdout) {
+		return url
+	}
+
+	return "\033]8;;" + url + "\033\\" + url + "\033]8;;\033\\"
+}
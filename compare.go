@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/olekukonko/tablewriter"
+
+	"wordef/dict"
+)
+
+const notFoundCell = "(not found)"
+
+// firstDefinitions returns the first definition of each part of speech,
+// keyed by part of speech, along with the parts of speech in order.
+func firstDefinitions(wordInfo dict.WordInfo) (definitions map[string]string, partsOfSpeech []string) {
+	definitions = make(map[string]string)
+
+	for _, m := range wordInfo.Meanings {
+		if len(m.Definitions) == 0 || definitions[m.PartOfSpeech] != "" {
+			continue
+		}
+
+		definitions[m.PartOfSpeech] = m.Definitions[0].Definition
+		partsOfSpeech = append(partsOfSpeech, m.PartOfSpeech)
+	}
+
+	return definitions, partsOfSpeech
+}
+
+func renderCompareTable(table *tablewriter.Table, results []dict.Result, opts searchOptions) {
+	header := make([]string, len(results))
+	phonetics := make([]string, len(results))
+	definitions := make([]map[string]string, len(results))
+
+	var partsOfSpeech []string
+
+	for i, r := range results {
+		header[i] = displayWord(r.Word)
+
+		if len(r.Entries) > 0 {
+			header[i] = displayWord(r.Entries[0].Word)
+		}
+
+		if r.Err != nil {
+			phonetics[i] = notFoundCell
+			continue
+		}
+
+		phonetics[i], _ = phoneticSpellings(r.Entries[0])
+
+		var pos []string
+		definitions[i], pos = firstDefinitions(r.Entries[0])
+
+		for _, p := range pos {
+			if !slices.Contains(partsOfSpeech, p) {
+				partsOfSpeech = append(partsOfSpeech, p)
+			}
+		}
+	}
+
+	table.SetHeader(header)
+	table.SetRowLine(true)
+	setColumnWidth(table, opts.width, max((terminalWidth()-7)/2, minDefinitionWidth))
+	table.Append(phonetics)
+
+	for _, pos := range partsOfSpeech {
+		row := make([]string, len(results))
+
+		for i := range results {
+			definition, ok := definitions[i][pos]
+
+			if ok {
+				row[i] = fmt.Sprintf("%s: %s", bold(pos, opts.color), definition)
+			}
+		}
+
+		table.Append(row)
+	}
+
+	table.Render()
+}
+
+// handleCompareCommand shows the first definition of each part of speech
+// of two words side by side.
+func handleCompareCommand(table *tablewriter.Table, words []string, cacheDir string, opts searchOptions) error {
+	if len(words) != 2 {
+		return errors.New("--compare expects exactly two words")
+	}
+
+	results := lookupWords(words, cacheDir, opts)
+
+	var errs []error
+
+	for _, r := range results {
+		if r.Err == nil {
+			continue
+		}
+
+		if !errors.Is(r.Err, dict.ErrWordNotFound) {
+			return r.Err
+		}
+
+		errs = append(errs, r.Err)
+	}
+
+	if len(errs) == len(words) {
+		return &batchError{errs: errs, total: len(words)}
+	}
+
+	renderCompareTable(table, results, opts)
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import "fmt"
+
+const bashCompletion = `# bash completion for wordef, load it with: source <(wordef completion bash)
+_wordef() {
+	local cur="${COMP_WORDS[COMP_CWORD]}"
+
+	if [[ "$cur" == -* ]]; then
+		return
+	fi
+
+	COMPREPLY=($(compgen -W "$(wordef --list-words 2>/dev/null)" -- "$cur"))
+}
+
+complete -F _wordef wordef
+`
+
+const zshCompletion = `#compdef wordef
+# zsh completion for wordef, load it with: source <(wordef completion zsh)
+_wordef() {
+	local -a saved
+	saved=(${(f)"$(wordef --list-words 2>/dev/null)"})
+	compadd -a saved
+}
+
+if [ "$funcstack[1]" = "_wordef" ]; then
+	_wordef "$@"
+else
+	compdef _wordef wordef
+fi
+`
+
+func handleCompletionCommand(shell string) error {
+	switch shell {
+	case "bash":
+		fmt.Print(bashCompletion)
+	case "zsh":
+		fmt.Print(zshCompletion)
+	default:
+		return fmt.Errorf("Unsupported shell %q, expected bash or zsh", shell)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"wordef/dict"
+)
+
+const configFileName = "config.json"
+
+// Config holds the defaults read from the config file. Settings are applied
+// in the order flag > environment variable > config file > built-in default.
+type Config struct {
+	Color    string `json:"color"`
+	Lang     string `json:"lang"`
+	Limit    int    `json:"limit"`
+	CacheDir string `json:"cacheDir"`
+}
+
+func configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to get user config directory: %w", err)
+	}
+
+	return filepath.Join(dir, "wordef", configFileName), nil
+}
+
+func defaultConfig() Config {
+	return Config{
+		Color: "auto",
+		Lang:  dict.DefaultLanguage,
+	}
+}
+
+// loadConfig reads the config file, settings missing from it, or a missing
+// file, keep their built-in defaults. A file that cannot be read or parsed
+// is reported with every setting at its default.
+func loadConfig() (config Config, err error) {
+	config = defaultConfig()
+
+	path, err := configPath()
+
+	if err != nil {
+		return config, err
+	}
+
+	rawJson, err := os.ReadFile(path)
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	}
+
+	if err != nil {
+		return config, fmt.Errorf("Failed to read config file: %w", err)
+	}
+
+	err = json.Unmarshal(rawJson, &config)
+
+	if err != nil {
+		return defaultConfig(), fmt.Errorf("Failed to parse config file %s: %w", path, err)
+	}
+
+	return config, nil
+}
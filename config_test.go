@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		want    Config
+		wantErr bool
+	}{
+		{
+			name: "missing file",
+			want: Config{Color: "auto", Lang: "en"},
+		},
+		{
+			name: "every setting",
+			file: `{"color":"never","lang":"fr","limit":3,"cacheDir":"/tmp/words"}`,
+			want: Config{Color: "never", Lang: "fr", Limit: 3, CacheDir: "/tmp/words"},
+		},
+		{
+			name: "some settings",
+			file: `{"limit":2}`,
+			want: Config{Color: "auto", Lang: "en", Limit: 2},
+		},
+		{
+			name:    "malformed",
+			file:    `{"limit":`,
+			want:    Config{Color: "auto", Lang: "en"},
+			wantErr: true,
+		},
+		{
+			name:    "wrong type",
+			file:    `{"color":"never","limit":"three"}`,
+			want:    Config{Color: "auto", Lang: "en"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", dir)
+
+			if tt.file != "" {
+				err := os.Mkdir(filepath.Join(dir, "wordef"), 0o700)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				err = os.WriteFile(filepath.Join(dir, "wordef", configFileName), []byte(tt.file), 0o600)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			config, err := loadConfig()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadConfig() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			if config != tt.want {
+				t.Errorf("loadConfig() = %+v, want %+v", config, tt.want)
+			}
+		})
+	}
+}
+
+func TestMalformedConfigFile(t *testing.T) {
+	bin := buildBinary(t)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"help", []string{"--help"}, "Commands:"},
+		{"version", []string{"--version"}, "wordef"},
+		{"list words", []string{"--list-words"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			configDir := filepath.Join(home, "config", "wordef")
+
+			err := os.MkdirAll(configDir, 0o700)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = os.WriteFile(filepath.Join(configDir, configFileName), []byte(`{"limit":`), 0o600)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			out, code := runBinary(t, bin, home, nil, nil, tt.args...)
+
+			if code != 0 {
+				t.Errorf("wordef %v exited with %d, want 0", tt.args, code)
+			}
+
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("wordef %v printed %q, want it to contain %q", tt.args, out, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+
+	"wordef/dict"
+)
+
+var csvHeader = []string{"word", "partOfSpeech", "definition", "example"}
+
+func writeCsvRows(w *csv.Writer, word string, wordInfo dict.WordInfo) error {
+	for _, m := range wordInfo.Meanings {
+		for _, d := range m.Definitions {
+			err := w.Write([]string{word, m.PartOfSpeech, d.Definition, d.Example})
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// handleCsvCommand writes the definitions of every word as a single CSV
+// document, words that cannot be found are reported on stderr.
+func handleCsvCommand(words []string, cacheDir string, opts searchOptions) error {
+	w := csv.NewWriter(os.Stdout)
+
+	err := w.Write(csvHeader)
+
+	if err != nil {
+		return fmt.Errorf("Failed to write CSV: %w", err)
+	}
+
+	err = forEachWordInfo(words, cacheDir, opts, func(wordInfo dict.WordInfo) error {
+		return writeCsvRows(w, wordInfo.Word, wordInfo)
+	})
+
+	w.Flush()
+
+	if w.Error() != nil {
+		return fmt.Errorf("Failed to write CSV: %w", w.Error())
+	}
+
+	return err
+}
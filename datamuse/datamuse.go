@@ -0,0 +1,91 @@
+// Package datamuse queries the Datamuse API for words related to a word or
+// phrase. It is kept apart from package dict, which only deals with
+// dictionary entries.
+package datamuse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const DefaultBaseURL = "https://api.datamuse.com/words"
+
+// BaseURL is the Datamuse words endpoint, query parameters are appended to it.
+var BaseURL = DefaultBaseURL
+
+var HTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// UserAgent is sent with every request.
+var UserAgent = "wordef"
+
+type Word struct {
+	Word         string   `json:"word"`
+	Score        int      `json:"score"`
+	NumSyllables int      `json:"numSyllables,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+}
+
+func query(ctx context.Context, params url.Values) (words []Word, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, BaseURL+"?"+params.Encode(), nil)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", UserAgent)
+
+	resp, err := HTTPClient.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Datamuse API returned status %d", resp.StatusCode)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&words)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse Datamuse response: %w", err)
+	}
+
+	return words, nil
+}
+
+// MeansLike returns up to max words whose meaning is close to phrase, best
+// match first.
+func MeansLike(ctx context.Context, phrase string, max int) ([]Word, error) {
+	params := url.Values{}
+	params.Set("ml", phrase)
+	params.Set("max", strconv.Itoa(max))
+
+	return query(ctx, params)
+}
+
+// Rhymes returns words that rhyme with word, best rhyme first.
+func Rhymes(ctx context.Context, word string) ([]string, error) {
+	params := url.Values{}
+	params.Set("rel_rhy", word)
+
+	found, err := query(ctx, params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rhymes := make([]string, 0, len(found))
+
+	for _, w := range found {
+		rhymes = append(rhymes, w.Word)
+	}
+
+	return rhymes, nil
+}
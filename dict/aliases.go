@@ -0,0 +1,121 @@
+package dict
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const aliasesFileName = "aliases.json"
+
+var ErrAliasCycle = errors.New("Alias cycle")
+
+// Aliases returns the saved aliases in dir, keyed by alias.
+func Aliases(dir string) (aliases map[string]string, err error) {
+	aliases = make(map[string]string)
+
+	rawJson, err := os.ReadFile(filepath.Join(dir, aliasesFileName))
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return aliases, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read aliases: %w", err)
+	}
+
+	err = json.Unmarshal(rawJson, &aliases)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse aliases: %w", err)
+	}
+
+	return aliases, nil
+}
+
+// SetAlias makes alias stand for word, an empty word removes the alias.
+func SetAlias(dir, alias, word string) error {
+	alias = NormalizeWord(alias)
+	word = NormalizeWord(word)
+
+	if alias == "" {
+		return errors.New("Alias must not be empty")
+	}
+
+	unlock, err := lockDir(dir)
+
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	aliases, err := Aliases(dir)
+
+	if err != nil {
+		return err
+	}
+
+	if word == "" {
+		delete(aliases, alias)
+	} else {
+		aliases[alias] = word
+
+		_, err = resolveAlias(aliases, alias)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	rawJson, err := json.MarshalIndent(aliases, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	err = writeFileAtomic(filepath.Join(dir, aliasesFileName), rawJson, FilePerm)
+
+	if err != nil {
+		return fmt.Errorf("Failed to write aliases: %w", err)
+	}
+
+	return nil
+}
+
+// ResolveAlias follows the aliases in dir from word to the word it stands
+// for, a word without an alias is returned as is.
+func ResolveAlias(dir, word string) (string, error) {
+	aliases, err := Aliases(dir)
+
+	if err != nil {
+		return "", err
+	}
+
+	return resolveAlias(aliases, NormalizeWord(word))
+}
+
+func resolveAlias(aliases map[string]string, word string) (string, error) {
+	seen := []string{word}
+
+	for {
+		target, ok := aliases[word]
+
+		if !ok {
+			return word, nil
+		}
+
+		for _, s := range seen {
+			if s == target {
+				return "", fmt.Errorf("%w: %s", ErrAliasCycle, strings.Join(append(seen, target), " -> "))
+			}
+		}
+
+		seen = append(seen, target)
+		word = target
+	}
+}
@@ -0,0 +1,103 @@
+package dict
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestResolveAlias(t *testing.T) {
+	tests := []struct {
+		name    string
+		aliases map[string]string
+		word    string
+		want    string
+		wantErr error
+	}{
+		{"no aliases", nil, "cat", "cat", nil},
+		{"not an alias", map[string]string{"s": "serendipity"}, "cat", "cat", nil},
+		{"alias", map[string]string{"s": "serendipity"}, "s", "serendipity", nil},
+		{"normalized", map[string]string{"s": "serendipity"}, " S. ", "serendipity", nil},
+		{"chain", map[string]string{"a": "b", "b": "c", "c": "cat"}, "a", "cat", nil},
+		{"cycle", map[string]string{"a": "b", "b": "a"}, "a", "", ErrAliasCycle},
+		{"self", map[string]string{"a": "a"}, "a", "", ErrAliasCycle},
+		{"cycle further down", map[string]string{"a": "b", "b": "c", "c": "b"}, "a", "", ErrAliasCycle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveAlias(tt.aliases, NormalizeWord(tt.word))
+
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("resolveAlias(%q) = %q, %v, want %q, %v", tt.word, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetAlias(t *testing.T) {
+	dir := t.TempDir()
+
+	steps := []struct {
+		alias, word string
+		wantErr     error
+		want        map[string]string
+	}{
+		{"S", "Serendipity", nil, map[string]string{"s": "serendipity"}},
+		{"ser", "s", nil, map[string]string{"s": "serendipity", "ser": "s"}},
+		{"serendipity", "ser", ErrAliasCycle, map[string]string{"s": "serendipity", "ser": "s"}},
+		{"s", "", nil, map[string]string{"ser": "s"}},
+	}
+
+	for _, step := range steps {
+		err := SetAlias(dir, step.alias, step.word)
+
+		if !errors.Is(err, step.wantErr) {
+			t.Fatalf("SetAlias(%q, %q) error = %v, want %v", step.alias, step.word, err, step.wantErr)
+		}
+
+		aliases, err := Aliases(dir)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(aliases) != len(step.want) {
+			t.Fatalf("after SetAlias(%q, %q) aliases = %v, want %v", step.alias, step.word, aliases, step.want)
+		}
+
+		for alias, word := range step.want {
+			if aliases[alias] != word {
+				t.Errorf("after SetAlias(%q, %q) aliases = %v, want %v", step.alias, step.word, aliases, step.want)
+			}
+		}
+	}
+}
+
+func TestSearchResolvesAliases(t *testing.T) {
+	var paths []string
+
+	testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(catJson))
+	})
+
+	dir := t.TempDir()
+
+	err := SetAlias(dir, "kitty", "cat")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Search(context.Background(), "Kitty", t.TempDir(), SearchOptions{AliasesDir: dir})
+
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	if len(paths) != 1 || paths[0] != "/en/cat" {
+		t.Errorf("API requests = %v, want [/en/cat]", paths)
+	}
+}
@@ -0,0 +1,226 @@
+package dict
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const DefaultTimeout = 10 * time.Second
+
+const DefaultBaseURL = "https://api.dictionaryapi.dev/api/v2/entries/"
+
+const DefaultLanguage = "en"
+
+var SupportedLanguages = []string{"en", "hi", "es", "fr", "ja", "ru", "de", "it", "ko", "pt-BR", "ar", "tr"}
+
+const DefaultMaxAttempts = 3
+
+// MaxAttempts is how many times a request is tried before giving up.
+var MaxAttempts = DefaultMaxAttempts
+
+// RetryBackoff is the delay before the first retry, doubling on each one after.
+var RetryBackoff = 200 * time.Millisecond
+
+// HTTPClient is used for every API request and can be replaced in tests.
+var HTTPClient = &http.Client{Timeout: DefaultTimeout}
+
+// UserAgent is sent with every API request.
+var UserAgent = "wordef"
+
+// BaseURL is the API endpoint, the language code and word are appended to it.
+var BaseURL = DefaultBaseURL
+
+func ValidateLanguage(lang string) error {
+	if slices.Contains(SupportedLanguages, lang) {
+		return nil
+	}
+
+	return fmt.Errorf("Unsupported language %q, expected one of: %s", lang, strings.Join(SupportedLanguages, ", "))
+}
+
+// ErrNotModified is returned by a conditional lookup when the cached
+// response is still current.
+var ErrNotModified = errors.New("Word not modified since it was cached")
+
+// Validators are the response headers that let a later request ask the API
+// whether a cached response has changed.
+type Validators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// FetchFromAPI requests a word from the API, retrying dropped connections
+// and 5xx and 429 responses up to MaxAttempts times with exponential backoff.
+func FetchFromAPI(ctx context.Context, word, lang string) (rawJson []byte, err error) {
+	rawJson, _, err = fetchConditional(ctx, word, lang, Validators{})
+
+	return rawJson, err
+}
+
+// fetchConditional is FetchFromAPI sending the validators of a cached
+// response, it returns ErrNotModified when the API answers 304.
+func fetchConditional(ctx context.Context, word, lang string, cached Validators) (rawJson []byte, fresh Validators, err error) {
+	err = ValidateWord(word)
+
+	if err != nil {
+		return nil, fresh, err
+	}
+
+	backoff := RetryBackoff
+	start := time.Now()
+	attempt := 1
+
+	for ; ; attempt++ {
+		rawJson, fresh, err = fetchOnce(ctx, word, lang, cached)
+
+		if err == nil || attempt >= MaxAttempts || !isRetryable(err) || ctx.Err() != nil {
+			break
+		}
+
+		Logger.Debug("Retrying API request", "word", word, "attempt", attempt, "backoff", backoff, "reason", err)
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+
+	if err != nil && ctx.Err() != nil {
+		return nil, fresh, fmt.Errorf("request cancelled: %w", ctx.Err())
+	}
+
+	var netErr net.Error
+
+	// Timeouts are not retried, so the attempt that timed out is the last.
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		elapsed := time.Since(start).Round(time.Millisecond)
+
+		if attempt > 1 {
+			return nil, fresh, fmt.Errorf("request timed out after %s, giving up after %d attempts", elapsed, attempt)
+		}
+
+		return nil, fresh, fmt.Errorf("request timed out after %s", elapsed)
+	}
+
+	return rawJson, fresh, err
+}
+
+// isRetryable reports whether a request that failed with err may succeed
+// if sent again: 5xx and 429 responses, dropped connections and temporary
+// network errors. Timeouts, TLS and DNS failures and bad URLs are not.
+func isRetryable(err error) bool {
+	var statusErr *APIStatusError
+
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500 || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	var netErr net.Error
+
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return false
+	}
+
+	var dnsErr *net.DNSError
+
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTemporary && !dnsErr.IsNotFound
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+
+	var temporary interface{ Temporary() bool }
+
+	return errors.As(err, &temporary) && temporary.Temporary()
+}
+
+func fetchOnce(ctx context.Context, word, lang string, cached Validators) (rawJson []byte, fresh Validators, err error) {
+	err = waitForRate(ctx)
+
+	if err != nil {
+		return nil, fresh, err
+	}
+
+	requestUrl := BaseURL + url.PathEscape(lang) + "/" + url.PathEscape(word)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestUrl, nil)
+
+	if err != nil {
+		return nil, fresh, fmt.Errorf("Failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", UserAgent)
+
+	if cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	if cached.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cached.LastModified)
+	}
+
+	Logger.Debug("Requesting word from API", "url", requestUrl)
+
+	resp, err := HTTPClient.Do(req)
+
+	if err != nil {
+		return nil, fresh, err
+	}
+
+	defer resp.Body.Close()
+
+	Logger.Debug("Received API response", "status", resp.StatusCode)
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, cached, ErrNotModified
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		notFound := &WordNotFoundError{Word: word}
+
+		body, err := io.ReadAll(resp.Body)
+
+		if err == nil {
+			apiErr := parseAPIError(body)
+
+			if apiErr != nil {
+				notFound.Details = apiErr.details()
+			}
+		}
+
+		return nil, fresh, notFound
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fresh, &APIStatusError{StatusCode: resp.StatusCode}
+	}
+
+	rawJson, err = io.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, fresh, fmt.Errorf("Failed to read response body: %w", err)
+	}
+
+	Logger.Debug("Read API response body", "bytes", len(rawJson))
+	metricsFrom(ctx).recordFetch(len(rawJson))
+
+	fresh = Validators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	return rawJson, fresh, nil
+}
@@ -0,0 +1,319 @@
+package dict
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFetchFromAPIRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		status       int
+		wantRequests int
+		wantErr      bool
+	}{
+		{"server error then success", 2, http.StatusInternalServerError, 3, false},
+		{"too many requests then success", 1, http.StatusTooManyRequests, 2, false},
+		{"server errors on every attempt", 3, http.StatusServiceUnavailable, 3, true},
+		{"not found is not retried", 3, http.StatusNotFound, 1, true},
+		{"bad request is not retried", 3, http.StatusBadRequest, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+
+			testServer(t, func(w http.ResponseWriter, r *http.Request) {
+				requests++
+
+				if requests <= tt.failures {
+					w.WriteHeader(tt.status)
+					return
+				}
+
+				w.Write([]byte(catJson))
+			})
+
+			rawJson, err := FetchFromAPI(context.Background(), "cat", "en")
+
+			if requests != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", requests, tt.wantRequests)
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FetchFromAPI() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && string(rawJson) != catJson {
+				t.Errorf("FetchFromAPI() = %s, want %s", rawJson, catJson)
+			}
+		})
+	}
+}
+
+func TestFetchFromAPIRetriesDroppedConnections(t *testing.T) {
+	requests := 0
+
+	testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if requests == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+
+			if err == nil {
+				conn.Close()
+			}
+
+			return
+		}
+
+		w.Write([]byte(catJson))
+	})
+
+	_, err := FetchFromAPI(context.Background(), "cat", "en")
+
+	if err != nil {
+		t.Fatalf("FetchFromAPI() error = %v", err)
+	}
+
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+}
+
+func TestFetchFromAPITimeout(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		wantMessage  *regexp.Regexp
+		wantRequests int
+	}{
+		{"first attempt", 0, regexp.MustCompile(`^request timed out after \d+ms$`), 1},
+		{"after a server error", 1, regexp.MustCompile(`^request timed out after \d+ms, giving up after 2 attempts$`), 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+
+			testServer(t, func(w http.ResponseWriter, r *http.Request) {
+				requests++
+
+				if requests <= tt.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+			})
+
+			setForTest(t, &HTTPClient, &http.Client{Timeout: 50 * time.Millisecond})
+
+			_, err := FetchFromAPI(context.Background(), "cat", "en")
+
+			if err == nil || !tt.wantMessage.MatchString(err.Error()) {
+				t.Errorf("FetchFromAPI() error = %v, want one matching %s", err, tt.wantMessage)
+			}
+
+			if requests != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+// timeoutError is a net.Error that timed out.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsRetryable(t *testing.T) {
+	_, schemeErr := http.Get("ftp://example.com/cat")
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"server error", &APIStatusError{StatusCode: http.StatusBadGateway}, true},
+		{"too many requests", &APIStatusError{StatusCode: http.StatusTooManyRequests}, true},
+		{"client error", &APIStatusError{StatusCode: http.StatusForbidden}, false},
+		{"connection reset", &url.Error{Op: "Get", Err: &net.OpError{Op: "read", Err: syscall.ECONNRESET}}, true},
+		{"connection closed", &url.Error{Op: "Get", Err: io.EOF}, true},
+		{"unexpected end of body", fmt.Errorf("Failed to read response body: %w", io.ErrUnexpectedEOF), true},
+		{"timeout", &url.Error{Op: "Get", Err: timeoutError{}}, false},
+		{"unknown host", &url.Error{Op: "Get", Err: &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", IsNotFound: true}}}, false},
+		{"temporary DNS failure", &net.DNSError{Err: "server misbehaving", IsTemporary: true}, true},
+		{"TLS error", &url.Error{Op: "Get", Err: &tls.CertificateVerificationError{Err: errors.New("unknown authority")}}, false},
+		{"unsupported scheme", schemeErr, false},
+		{"connection refused", &url.Error{Op: "Get", Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryable(tt.err); got != tt.want {
+				t.Errorf("isRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchFromAPIEscapesWords(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"cat", "/en/cat"},
+		{"ice cream", "/en/ice%20cream"},
+		{"and/or", "/en/and%2For"},
+		{"café", "/en/caf%C3%A9"},
+		{"naïve?", "/en/na%C3%AFve%3F"},
+		{"100%", "/en/100%25"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			var got string
+
+			testServer(t, func(w http.ResponseWriter, r *http.Request) {
+				got = r.URL.EscapedPath()
+				w.Write([]byte(catJson))
+			})
+
+			_, err := FetchFromAPI(context.Background(), tt.word, "en")
+
+			if err != nil {
+				t.Fatalf("FetchFromAPI() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("requested %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchFromAPIRejectsInvalidWords(t *testing.T) {
+	tests := []struct {
+		name string
+		word string
+	}{
+		{"empty", ""},
+		{"blank", " \t"},
+		{"newline", "ca\nt"},
+		{"null byte", "ca\x00t"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testServer(t, func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("invalid word %q was requested", tt.word)
+			})
+
+			_, err := FetchFromAPI(context.Background(), tt.word, "en")
+
+			if err == nil {
+				t.Errorf("FetchFromAPI(%q) error = nil, want an error", tt.word)
+			}
+		})
+	}
+}
+
+func TestSearchRevalidatesWithValidators(t *testing.T) {
+	const lastModified = "Mon, 02 Jan 2006 15:04:05 GMT"
+
+	tests := []struct {
+		name       string
+		header     string
+		value      string
+		condHeader string
+	}{
+		{"etag", "ETag", `"v1"`, "If-None-Match"},
+		{"last modified", "Last-Modified", lastModified, "If-Modified-Since"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var conditions []string
+
+			testServer(t, func(w http.ResponseWriter, r *http.Request) {
+				conditions = append(conditions, r.Header.Get(tt.condHeader))
+
+				if r.Header.Get(tt.condHeader) == tt.value {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+
+				w.Header().Set(tt.header, tt.value)
+				w.Write([]byte(catJson))
+			})
+
+			setForTest(t, &CacheTTL, time.Hour)
+
+			cacheDir := t.TempDir()
+
+			_, err := Search(context.Background(), "cat", cacheDir, SearchOptions{})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			old := time.Now().Add(-2 * time.Hour)
+			wordPath := cachePath("cat", cacheDir)
+
+			err = os.Chtimes(wordPath, old, old)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			entries, err := Search(context.Background(), "cat", cacheDir, SearchOptions{})
+
+			if err != nil {
+				t.Fatalf("Search() after 304 error = %v", err)
+			}
+
+			if len(entries) != 1 || entries[0].Word != "cat" {
+				t.Errorf("Search() after 304 = %v, want the cached entry", entries)
+			}
+
+			if want := []string{"", tt.value}; !slices.Equal(conditions, want) {
+				t.Errorf("%s headers sent = %q, want %q", tt.condHeader, conditions, want)
+			}
+
+			info, err := os.Stat(wordPath)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if time.Since(info.ModTime()) > time.Minute {
+				t.Errorf("cache file modified at %s, want it marked fresh", info.ModTime())
+			}
+
+			_, err = FetchFromCache("cat", cacheDir)
+
+			if err != nil {
+				t.Errorf("FetchFromCache() after 304 error = %v, want fresh", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package dict
+
+import "context"
+
+const DefaultWorkers = 4
+
+type Result struct {
+	Word    string
+	Entries []WordInfo
+	Err     error
+}
+
+// SearchAll looks up words concurrently with at most workers lookups in
+// flight and returns the results in the order of words. Words that normalize
+// to the same cache key are only looked up once, so no two lookups ever write
+// the same cache file.
+func SearchAll(ctx context.Context, words []string, cacheDir string, opts SearchOptions, workers int) []Result {
+	results := make([]Result, 0, len(words))
+
+	for r := range SearchStream(ctx, words, cacheDir, opts, workers) {
+		results = append(results, r)
+	}
+
+	return results
+}
+
+// SearchStream is SearchAll sending each result, in the order of words, as
+// soon as it and the results before it are ready.
+func SearchStream(ctx context.Context, words []string, cacheDir string, opts SearchOptions, workers int) <-chan Result {
+	if workers < 1 {
+		workers = 1
+	}
+
+	var unique []string
+	indexes := make(map[string]int)
+
+	for _, word := range words {
+		key := NormalizeWord(word)
+
+		if _, ok := indexes[key]; !ok {
+			indexes[key] = len(unique)
+			unique = append(unique, word)
+		}
+	}
+
+	found := make([]Result, len(unique))
+	done := make([]chan struct{}, len(unique))
+
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	jobs := make(chan int)
+
+	for range min(workers, len(unique)) {
+		go func() {
+			for i := range jobs {
+				entries, err := Search(ctx, unique[i], cacheDir, opts)
+				found[i] = Result{Word: unique[i], Entries: entries, Err: err}
+				close(done[i])
+			}
+		}()
+	}
+
+	go func() {
+		for i := range unique {
+			jobs <- i
+		}
+
+		close(jobs)
+	}()
+
+	results := make(chan Result)
+
+	go func() {
+		for _, word := range words {
+			i := indexes[NormalizeWord(word)]
+			<-done[i]
+
+			r := found[i]
+			r.Word = word
+			results <- r
+		}
+
+		close(results)
+	}()
+
+	return results
+}
@@ -0,0 +1,68 @@
+package dict
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSearchAll(t *testing.T) {
+	const latency = 100 * time.Millisecond
+
+	words := []string{"one", "two", "three", "four", "Two", "five", "six", "seven", "eight"}
+
+	tests := []struct {
+		name    string
+		workers int
+		maxTime time.Duration
+	}{
+		{"sequential", 1, 0},
+		{"concurrent", 4, 4 * latency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+
+			testServer(t, func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				time.Sleep(latency)
+
+				fmt.Fprintf(w, `[{"word":%q,"meanings":[]}]`, path.Base(r.URL.Path))
+			})
+
+			start := time.Now()
+			results := SearchAll(context.Background(), words, t.TempDir(), SearchOptions{}, tt.workers)
+			elapsed := time.Since(start)
+
+			var got []string
+
+			for _, r := range results {
+				if r.Err != nil {
+					t.Fatalf("SearchAll() error for %s = %v", r.Word, r.Err)
+				}
+
+				got = append(got, r.Entries[0].Word)
+			}
+
+			want := []string{"one", "two", "three", "four", "two", "five", "six", "seven", "eight"}
+
+			if !slices.Equal(got, want) {
+				t.Errorf("SearchAll() words = %v, want %v", got, want)
+			}
+
+			if n := requests.Load(); n != 8 {
+				t.Errorf("requests = %d, want 8, one for each distinct word", n)
+			}
+
+			if tt.maxTime > 0 && elapsed > tt.maxTime {
+				t.Errorf("SearchAll() took %s with %d workers, want at most %s", elapsed, tt.workers, tt.maxTime)
+			}
+		})
+	}
+}
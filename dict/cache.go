@@ -0,0 +1,627 @@
+package dict
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrCacheExpired = errors.New("Cached word has expired")
+
+var ErrAlreadyCached = errors.New("Word already saved to file")
+
+var ErrCorruptCache = errors.New("Cache file is corrupted")
+
+// Cache directories and files are only accessible to their owner.
+const (
+	DirPerm  fs.FileMode = 0o700
+	FilePerm fs.FileMode = 0o600
+)
+
+// CacheTTL is how long a cached word stays fresh, zero keeps words forever.
+var CacheTTL time.Duration
+
+// CacheMax is the most words kept in a cache directory, zero means no limit.
+var CacheMax int
+
+// Compress makes SaveToCache write gzip compressed files, plain and
+// compressed cache files are both always readable.
+var Compress bool
+
+const (
+	cacheExt           = ".json"
+	compressedCacheExt = ".json.gz"
+)
+
+// CacheDir returns the default cache directory in the user cache directory,
+// such as $XDG_CACHE_HOME/wordef, creating it. The path is returned even when
+// it cannot be created.
+func CacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to get user cache directory: %w", err)
+	}
+
+	path := filepath.Join(dir, "wordef")
+
+	err = os.MkdirAll(path, DirPerm)
+
+	if err != nil {
+		return path, fmt.Errorf("Failed to create cache directory: %w", err)
+	}
+
+	return path, nil
+}
+
+// ConfigDir returns the directory of the config file and of the data that
+// is not cache, such as aliases, favorites and the history, creating it. The
+// path is returned even when it cannot be created.
+func ConfigDir() (string, error) {
+	dir, err := os.UserConfigDir()
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to get user config directory: %w", err)
+	}
+
+	path := filepath.Join(dir, "wordef")
+
+	err = os.MkdirAll(path, DirPerm)
+
+	if err != nil {
+		return path, fmt.Errorf("Failed to create app directory: %w", err)
+	}
+
+	return path, nil
+}
+
+// LanguageCacheDir returns the directory of a language inside cacheDir,
+// creating it. The path is returned even when it cannot be created.
+func LanguageCacheDir(cacheDir, lang string) (string, error) {
+	path := filepath.Join(cacheDir, lang)
+
+	err := os.MkdirAll(path, DirPerm)
+
+	if err != nil {
+		return path, fmt.Errorf("Failed to create language directory: %w", err)
+	}
+
+	return path, nil
+}
+
+// Writable reports whether files can be created in dir.
+func Writable(dir string) bool {
+	file, err := os.CreateTemp(dir, ".wordef-*.tmp")
+
+	if err != nil {
+		return false
+	}
+
+	file.Close()
+	os.Remove(file.Name())
+
+	return true
+}
+
+// cacheFileReplacer keeps words containing slashes in a single file.
+var cacheFileReplacer = strings.NewReplacer("%", "%25", "/", "%2F")
+
+func cachePath(word, cacheDir string) string {
+	return path.Join(cacheDir, cacheFileReplacer.Replace(NormalizeWord(word))+cacheExt)
+}
+
+func compressedCachePath(word, cacheDir string) string {
+	return path.Join(cacheDir, cacheFileReplacer.Replace(NormalizeWord(word))+compressedCacheExt)
+}
+
+// cachedWordName returns the word a cache file belongs to, ok is false for
+// files that are not cache files.
+func cachedWordName(fileName string) (word string, ok bool) {
+	if strings.HasSuffix(fileName, compressedCacheExt) {
+		word = strings.TrimSuffix(fileName, compressedCacheExt)
+	} else if strings.HasSuffix(fileName, cacheExt) {
+		word = strings.TrimSuffix(fileName, cacheExt)
+	} else {
+		return "", false
+	}
+
+	word, err := url.PathUnescape(word)
+
+	return word, err == nil
+}
+
+// findCacheFile returns the cache file of a word, preferring the compressed
+// one when both exist.
+func findCacheFile(word, cacheDir string) (wordPath string, info fs.FileInfo, err error) {
+	for _, wordPath = range []string{compressedCachePath(word, cacheDir), cachePath(word, cacheDir)} {
+		info, err = os.Stat(wordPath)
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			return wordPath, info, err
+		}
+	}
+
+	return wordPath, nil, err
+}
+
+func readCacheFile(wordPath string) (rawJson []byte, err error) {
+	file, err := os.Open(wordPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	var reader io.Reader = file
+
+	if strings.HasSuffix(wordPath, ".gz") {
+		gzipReader, err := gzip.NewReader(file)
+
+		if err != nil {
+			return nil, err
+		}
+
+		defer gzipReader.Close()
+
+		reader = gzipReader
+	}
+
+	return io.ReadAll(reader)
+}
+
+func readCachedWord(word, cacheDir string) (rawJson []byte, err error) {
+	wordPath, _, err := findCacheFile(word, cacheDir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := readCacheFile(wordPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeCacheEntry(data)
+}
+
+const cacheSchemaVersion = 1
+
+// cacheEntry is what a cache file holds, older versions of wordef saved the
+// bare API response instead.
+type cacheEntry struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	Raw           json.RawMessage `json:"raw"`
+	FetchedAt     time.Time       `json:"fetchedAt"`
+	Validators
+}
+
+func encodeCacheEntry(rawJson []byte, validators Validators) ([]byte, error) {
+	return json.Marshal(cacheEntry{
+		SchemaVersion: cacheSchemaVersion,
+		Raw:           rawJson,
+		FetchedAt:     time.Now(),
+		Validators:    validators,
+	})
+}
+
+// decodeCacheEntry returns the API response saved in a cache file, in
+// either the current or the bare format.
+func decodeCacheEntry(data []byte) (rawJson []byte, err error) {
+	entry, err := decodeCacheFile(data)
+
+	return entry.Raw, err
+}
+
+func decodeCacheFile(data []byte) (entry cacheEntry, err error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return cacheEntry{Raw: data}, nil
+	}
+
+	err = json.Unmarshal(data, &entry)
+
+	if err != nil {
+		return entry, fmt.Errorf("Failed to decode cache entry: %w", err)
+	}
+
+	if entry.SchemaVersion > cacheSchemaVersion {
+		return entry, fmt.Errorf("Cache entry has unsupported schema version %d", entry.SchemaVersion)
+	}
+
+	return entry, nil
+}
+
+// cachedValidators returns the validators saved with a cached word, they are
+// empty when the word is not cached or was saved without them.
+func cachedValidators(word, cacheDir string) Validators {
+	wordPath, _, err := findCacheFile(word, cacheDir)
+
+	if err != nil {
+		return Validators{}
+	}
+
+	data, err := readCacheFile(wordPath)
+
+	if err != nil {
+		return Validators{}
+	}
+
+	entry, err := decodeCacheFile(data)
+
+	if err != nil {
+		return Validators{}
+	}
+
+	return entry.Validators
+}
+
+// revalidateCache returns the cached response of a word the API reported as
+// not modified and marks it fresh again without rewriting it.
+func revalidateCache(word, cacheDir string) (rawJson []byte, err error) {
+	wordPath, _, err := findCacheFile(word, cacheDir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rawJson, err = readCachedWord(word, cacheDir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	return rawJson, os.Chtimes(wordPath, now, now)
+}
+
+func compress(rawJson []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	writer := gzip.NewWriter(&buf)
+
+	_, err := writer.Write(rawJson)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = writer.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeFileAtomic writes data to a temporary file next to name and renames
+// it into place, so an interrupted write never leaves a partial file.
+func writeFileAtomic(name string, data []byte, perm os.FileMode) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".wordef-*.tmp")
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	_, err = tmp.Write(data)
+
+	if err != nil {
+		return err
+	}
+
+	err = tmp.Chmod(perm)
+
+	if err != nil {
+		return err
+	}
+
+	err = tmp.Close()
+
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), name)
+}
+
+// SaveToCache saves the API response of a word. Processes saving to the
+// same cacheDir at the same time take turns.
+func SaveToCache(word string, rawJson []byte, cacheDir string, overwrite bool) error {
+	return saveToCache(word, rawJson, cacheDir, overwrite, Validators{})
+}
+
+func saveToCache(word string, rawJson []byte, cacheDir string, overwrite bool, validators Validators) error {
+	unlock, err := lockDir(cacheDir)
+
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	_, _, err = findCacheFile(word, cacheDir)
+
+	if err == nil && !overwrite {
+		return ErrAlreadyCached
+	}
+
+	wordPath := cachePath(word, cacheDir)
+	otherPath := compressedCachePath(word, cacheDir)
+
+	data, err := encodeCacheEntry(rawJson, validators)
+
+	if err != nil {
+		return fmt.Errorf("Failed to encode cache entry: %w", err)
+	}
+
+	if Compress {
+		wordPath, otherPath = otherPath, wordPath
+
+		data, err = compress(data)
+
+		if err != nil {
+			return fmt.Errorf("Failed to compress cache file: %w", err)
+		}
+	}
+
+	err = writeFileAtomic(wordPath, data, FilePerm)
+
+	if err != nil {
+		return fmt.Errorf("Failed to write cache file to app directory: %w", err)
+	}
+
+	os.Remove(otherPath)
+
+	if CacheMax > 0 {
+		return enforceCacheLimit(cacheDir, CacheMax)
+	}
+
+	return nil
+}
+
+// enforceCacheLimit evicts the least recently written words until at most
+// max words are left in cacheDir.
+func enforceCacheLimit(cacheDir string, max int) error {
+	dirEntries, err := os.ReadDir(cacheDir)
+
+	if err != nil {
+		return fmt.Errorf("Failed to read cache directory: %w", err)
+	}
+
+	var entries []CacheEntryInfo
+
+	for _, d := range dirEntries {
+		word, ok := cachedWordName(d.Name())
+
+		if d.IsDir() || !ok {
+			continue
+		}
+
+		info, err := d.Info()
+
+		if err != nil {
+			return fmt.Errorf("Failed to read cache directory: %w", err)
+		}
+
+		entries = append(entries, CacheEntryInfo{Word: word, ModTime: info.ModTime()})
+	}
+
+	if len(entries) <= max {
+		return nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ModTime.Before(entries[j].ModTime)
+	})
+
+	for _, entry := range entries[:len(entries)-max] {
+		err = DeleteFromCache(entry.Word, cacheDir)
+
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		Logger.Debug("evicted word from cache", "word", entry.Word)
+	}
+
+	return nil
+}
+
+// IsCached reports whether word is saved in cacheDir, expired or not.
+func IsCached(word, cacheDir string) bool {
+	_, _, err := findCacheFile(word, cacheDir)
+
+	return err == nil
+}
+
+func FetchFromCache(word, cacheDir string) (rawJson []byte, err error) {
+	wordPath, info, err := findCacheFile(word, cacheDir)
+
+	if err != nil {
+		return nil, fmt.Errorf("Word not found in cache: %w", err)
+	}
+
+	if CacheTTL > 0 && time.Since(info.ModTime()) > CacheTTL {
+		return nil, ErrCacheExpired
+	}
+
+	data, err := readCacheFile(wordPath)
+
+	if errors.Is(err, fs.ErrPermission) {
+		return nil, fmt.Errorf("failed to read cache file: %w", err)
+	}
+
+	if err == nil {
+		rawJson, err = decodeCacheEntry(data)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptCache, err)
+	}
+
+	return rawJson, nil
+}
+
+func DeleteFromCache(word, cacheDir string) error {
+	removed := false
+
+	for _, wordPath := range []string{compressedCachePath(word, cacheDir), cachePath(word, cacheDir)} {
+		err := os.Remove(wordPath)
+
+		if err == nil {
+			removed = true
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("Failed to remove cache file: %w", err)
+		}
+	}
+
+	if !removed {
+		return fmt.Errorf("Failed to remove cache file: %w", fs.ErrNotExist)
+	}
+
+	return nil
+}
+
+func CachedWords(cacheDir string) (words []string, err error) {
+	seen := make(map[string]bool)
+
+	err = filepath.WalkDir(cacheDir, func(s string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		word, ok := cachedWordName(d.Name())
+
+		if ok && !d.IsDir() && !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get cached words from cache directory: %w", err)
+	}
+
+	return words, nil
+}
+
+func ClearCache(cacheDir string) (removed int, err error) {
+	err = filepath.WalkDir(cacheDir, func(s string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		if filepath.Ext(d.Name()) == notFoundExt {
+			return os.Remove(s)
+		}
+
+		if _, ok := cachedWordName(d.Name()); !ok {
+			return nil
+		}
+
+		err = os.Remove(s)
+
+		if err != nil {
+			return err
+		}
+
+		removed++
+
+		return nil
+	})
+
+	if err != nil {
+		return removed, fmt.Errorf("Failed to clear cache directory: %w", err)
+	}
+
+	return removed, nil
+}
+
+type CacheEntryInfo struct {
+	Word    string
+	ModTime time.Time
+}
+
+type CacheStats struct {
+	Count int
+	// TotalBytes is the size of the saved words, lock, temporary and not
+	// found files are left out.
+	TotalBytes int64
+	Oldest     CacheEntryInfo
+	Newest     CacheEntryInfo
+}
+
+func Stats(cacheDir string) (stats CacheStats, err error) {
+	err = filepath.WalkDir(cacheDir, func(s string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		word, ok := cachedWordName(d.Name())
+
+		if !ok {
+			return nil
+		}
+
+		info, err := d.Info()
+
+		if err != nil {
+			return err
+		}
+
+		stats.TotalBytes += info.Size()
+
+		entry := CacheEntryInfo{
+			Word:    word,
+			ModTime: info.ModTime(),
+		}
+
+		if stats.Count == 0 || entry.ModTime.Before(stats.Oldest.ModTime) {
+			stats.Oldest = entry
+		}
+
+		if stats.Count == 0 || entry.ModTime.After(stats.Newest.ModTime) {
+			stats.Newest = entry
+		}
+
+		stats.Count++
+
+		return nil
+	})
+
+	if err != nil {
+		return stats, fmt.Errorf("Failed to read cache statistics: %w", err)
+	}
+
+	return stats, nil
+}
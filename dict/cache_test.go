@@ -0,0 +1,612 @@
+package dict
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+)
+
+// writeFiles creates files with the given contents in dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), FilePerm)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// fileNames returns the names of the files directly in dir, sorted.
+func fileNames(t *testing.T, dir string) (names []string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() && entry.Name() != lockFileName {
+			names = append(names, entry.Name())
+		}
+	}
+
+	return names
+}
+
+func TestLanguageCacheDirLeavesRootFiles(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		root map[string]string
+	}{
+		{"project files", "en", map[string]string{"package.json": `{"name":"proj"}`, "tsconfig.json": "{}"}},
+		{"words saved in a chosen directory", "en", map[string]string{"Cat.json": catJson}},
+		{"other language", "fr", map[string]string{"Cat.json": catJson}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheDir := t.TempDir()
+			writeFiles(t, cacheDir, tt.root)
+
+			langDir, err := LanguageCacheDir(cacheDir, tt.lang)
+
+			if err != nil {
+				t.Fatalf("LanguageCacheDir() error = %v", err)
+			}
+
+			var want []string
+
+			for name := range tt.root {
+				want = append(want, name)
+			}
+
+			slices.Sort(want)
+
+			if got := fileNames(t, cacheDir); !slices.Equal(got, want) {
+				t.Errorf("files left in cache directory = %v, want %v", got, want)
+			}
+
+			if got := fileNames(t, langDir); len(got) > 0 {
+				t.Errorf("files moved into language directory: %v", got)
+			}
+		})
+	}
+}
+
+func TestFetchFromCacheTTL(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		age     time.Duration
+		wantErr error
+	}{
+		{"disabled", 0, 365 * 24 * time.Hour, nil},
+		{"fresh", time.Hour, time.Minute, nil},
+		{"expired", time.Hour, 2 * time.Hour, ErrCacheExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setForTest(t, &CacheTTL, tt.ttl)
+
+			cacheDir := t.TempDir()
+
+			err := SaveToCache("cat", []byte(catJson), cacheDir, false)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			modTime := time.Now().Add(-tt.age)
+
+			err = os.Chtimes(cachePath("cat", cacheDir), modTime, modTime)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = FetchFromCache("cat", cacheDir)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("FetchFromCache() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSaveToCacheOverwrite(t *testing.T) {
+	const newJson = `[{"word":"cat","meanings":[]}]`
+
+	tests := []struct {
+		name      string
+		overwrite bool
+		wantErr   error
+		want      string
+	}{
+		{"kept by default", false, ErrAlreadyCached, catJson},
+		{"overwritten", true, nil, newJson},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheDir := t.TempDir()
+
+			err := SaveToCache("cat", []byte(catJson), cacheDir, false)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = SaveToCache("cat", []byte(newJson), cacheDir, tt.overwrite)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SaveToCache() error = %v, want %v", err, tt.wantErr)
+			}
+
+			data, err := os.ReadFile(cachePath("cat", cacheDir))
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rawJson, err := decodeCacheEntry(data)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(rawJson) != tt.want {
+				t.Errorf("file on disk holds %s, want %s", rawJson, tt.want)
+			}
+		})
+	}
+}
+
+func TestStats(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name  string
+		files map[string]time.Duration
+		other []string
+		want  CacheStats
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:  "words in language directories",
+			files: map[string]time.Duration{"en/cat.json": 2 * time.Hour, "en/dog.json.gz": time.Hour, "fr/chat.json": 3 * time.Hour},
+			want: CacheStats{
+				Count:      3,
+				TotalBytes: 30,
+				Oldest:     CacheEntryInfo{Word: "chat", ModTime: now.Add(-3 * time.Hour)},
+				Newest:     CacheEntryInfo{Word: "dog", ModTime: now.Add(-time.Hour)},
+			},
+		},
+		{
+			name:  "other files left out",
+			files: map[string]time.Duration{"en/cat.json": time.Hour},
+			other: []string{"en/qwxz.notfound", "en/.wordef.lock", "en/.wordef-123.tmp"},
+			want: CacheStats{
+				Count:      1,
+				TotalBytes: 10,
+				Oldest:     CacheEntryInfo{Word: "cat", ModTime: now.Add(-time.Hour)},
+				Newest:     CacheEntryInfo{Word: "cat", ModTime: now.Add(-time.Hour)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheDir := t.TempDir()
+
+			for _, dir := range []string{"en", "fr"} {
+				err := os.Mkdir(filepath.Join(cacheDir, dir), DirPerm)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for name, age := range tt.files {
+				wordPath := filepath.Join(cacheDir, name)
+
+				err := os.WriteFile(wordPath, []byte("0123456789"), FilePerm)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				err = os.Chtimes(wordPath, now.Add(-age), now.Add(-age))
+
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for _, name := range tt.other {
+				err := os.WriteFile(filepath.Join(cacheDir, name), []byte("not a word"), FilePerm)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			stats, err := Stats(cacheDir)
+
+			if err != nil {
+				t.Fatalf("Stats() error = %v", err)
+			}
+
+			if stats.Count != tt.want.Count || stats.TotalBytes != tt.want.TotalBytes {
+				t.Errorf("Stats() = %d words, %d bytes, want %d, %d", stats.Count, stats.TotalBytes, tt.want.Count, tt.want.TotalBytes)
+			}
+
+			if stats.Oldest.Word != tt.want.Oldest.Word || !stats.Oldest.ModTime.Equal(tt.want.Oldest.ModTime) {
+				t.Errorf("Stats().Oldest = %v, want %v", stats.Oldest, tt.want.Oldest)
+			}
+
+			if stats.Newest.Word != tt.want.Newest.Word || !stats.Newest.ModTime.Equal(tt.want.Newest.ModTime) {
+				t.Errorf("Stats().Newest = %v, want %v", stats.Newest, tt.want.Newest)
+			}
+		})
+	}
+}
+
+func TestCompressedCacheRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		compress bool
+		wantFile string
+	}{
+		{"plain", false, "cat.json"},
+		{"compressed", true, "cat.json.gz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setForTest(t, &Compress, tt.compress)
+
+			cacheDir := t.TempDir()
+
+			err := SaveToCache("cat", []byte(catJson), cacheDir, false)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := fileNames(t, cacheDir); !slices.Equal(got, []string{tt.wantFile}) {
+				t.Errorf("files in cache directory = %v, want %v", got, []string{tt.wantFile})
+			}
+
+			// Files written either way are read whatever the setting is now.
+			setForTest(t, &Compress, !tt.compress)
+
+			rawJson, err := FetchFromCache("cat", cacheDir)
+
+			if err != nil {
+				t.Fatalf("FetchFromCache() error = %v", err)
+			}
+
+			if string(rawJson) != catJson {
+				t.Errorf("FetchFromCache() = %s, want %s", rawJson, catJson)
+			}
+
+			words, err := CachedWords(cacheDir)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(words, []string{"cat"}) {
+				t.Errorf("CachedWords() = %v, want [cat]", words)
+			}
+		})
+	}
+}
+
+func TestSaveToCacheReplacesOtherFormat(t *testing.T) {
+	cacheDir := t.TempDir()
+
+	err := SaveToCache("cat", []byte(catJson), cacheDir, false)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setForTest(t, &Compress, true)
+
+	err = SaveToCache("cat", []byte(catJson), cacheDir, true)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := fileNames(t, cacheDir), []string{"cat.json.gz"}; !slices.Equal(got, want) {
+		t.Errorf("files in cache directory = %v, want %v", got, want)
+	}
+}
+
+func TestEnforceCacheLimit(t *testing.T) {
+	words := []string{"ant", "bee", "cat", "dog", "eel"}
+
+	tests := []struct {
+		name string
+		max  int
+		want []string
+	}{
+		{"under the limit", 10, []string{"ant.json", "bee.json", "cat.json", "dog.json", "eel.json"}},
+		{"at the limit", 5, []string{"ant.json", "bee.json", "cat.json", "dog.json", "eel.json"}},
+		{"oldest evicted", 2, []string{"dog.json", "eel.json"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheDir := t.TempDir()
+			now := time.Now()
+
+			for i, word := range words {
+				err := SaveToCache(word, []byte(catJson), cacheDir, false)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				modTime := now.Add(time.Duration(i-len(words)) * time.Hour)
+
+				err = os.Chtimes(cachePath(word, cacheDir), modTime, modTime)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := enforceCacheLimit(cacheDir, tt.max)
+
+			if err != nil {
+				t.Fatalf("enforceCacheLimit() error = %v", err)
+			}
+
+			if got := fileNames(t, cacheDir); !slices.Equal(got, tt.want) {
+				t.Errorf("files left = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveToCacheEnforcesCacheMax(t *testing.T) {
+	setForTest(t, &CacheMax, 2)
+
+	cacheDir := t.TempDir()
+	start := time.Now().Add(-time.Hour)
+
+	for i, word := range []string{"ant", "bee", "cat"} {
+		err := SaveToCache(word, []byte(catJson), cacheDir, false)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		modTime := start.Add(time.Duration(i) * time.Minute)
+
+		err = os.Chtimes(cachePath(word, cacheDir), modTime, modTime)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got, want := fileNames(t, cacheDir), []string{"bee.json", "cat.json"}; !slices.Equal(got, want) {
+		t.Errorf("files left = %v, want %v", got, want)
+	}
+}
+
+func TestFetchFromCacheEntryFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		want    string
+		wantErr error
+	}{
+		{"bare array", catJson, catJson, nil},
+		{"bare array with whitespace", "\n  " + catJson, "\n  " + catJson, nil},
+		{"versioned", `{"schemaVersion":1,"raw":` + catJson + `,"fetchedAt":"2024-01-02T03:04:05Z"}`, catJson, nil},
+		{"versioned with validators", `{"schemaVersion":1,"raw":` + catJson + `,"fetchedAt":"2024-01-02T03:04:05Z","etag":"\"v1\""}`, catJson, nil},
+		{"newer version", `{"schemaVersion":2,"raw":` + catJson + `}`, "", ErrCorruptCache},
+		{"not json", `not json`, "", ErrCorruptCache},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheDir := t.TempDir()
+			writeFiles(t, cacheDir, map[string]string{"cat.json": tt.file})
+
+			rawJson, err := FetchFromCache("cat", cacheDir)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FetchFromCache() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if string(rawJson) != tt.want {
+				t.Errorf("FetchFromCache() = %s, want %s", rawJson, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveToCacheWritesVersionedEntry(t *testing.T) {
+	cacheDir := t.TempDir()
+	before := time.Now()
+
+	err := saveToCache("cat", []byte(catJson), cacheDir, false, Validators{ETag: `"v1"`})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(cachePath("cat", cacheDir))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entry cacheEntry
+
+	err = json.Unmarshal(data, &entry)
+
+	if err != nil {
+		t.Fatalf("cache file is not a versioned entry: %v", err)
+	}
+
+	if entry.SchemaVersion != cacheSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", entry.SchemaVersion, cacheSchemaVersion)
+	}
+
+	if string(entry.Raw) != catJson {
+		t.Errorf("Raw = %s, want %s", entry.Raw, catJson)
+	}
+
+	if entry.FetchedAt.Before(before) {
+		t.Errorf("FetchedAt = %s, want after %s", entry.FetchedAt, before)
+	}
+
+	if entry.ETag != `"v1"` {
+		t.Errorf("ETag = %s, want \"v1\"", entry.ETag)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, name string)
+		wantErr bool
+	}{
+		{
+			name:  "new file",
+			setup: func(t *testing.T, name string) {},
+		},
+		{
+			name: "replaces file",
+			setup: func(t *testing.T, name string) {
+				writeFiles(t, filepath.Dir(name), map[string]string{filepath.Base(name): "old"})
+			},
+		},
+		{
+			name: "rename fails",
+			setup: func(t *testing.T, name string) {
+				// A directory that is not empty cannot be replaced by a file.
+				err := os.MkdirAll(filepath.Join(name, "child"), DirPerm)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			name := filepath.Join(dir, "cat.json")
+
+			tt.setup(t, name)
+
+			err := writeFileAtomic(name, []byte(catJson), FilePerm)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("writeFileAtomic() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			temps, err := filepath.Glob(filepath.Join(dir, ".wordef-*.tmp"))
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(temps) > 0 {
+				t.Errorf("temporary files left behind: %v", temps)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			data, err := os.ReadFile(name)
+
+			if err != nil || string(data) != catJson {
+				t.Errorf("file holds %q, %v, want %q", data, err, catJson)
+			}
+		})
+	}
+}
+
+func TestCachePermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes do not apply on Windows")
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	cacheDir, err := CacheDir()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	langDir, err := LanguageCacheDir(cacheDir, "en")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = SaveToCache("cat", []byte(catJson), langDir, false)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setForTest(t, &Compress, true)
+
+	err = SaveToCache("dog", []byte(catJson), langDir, false)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want fs.FileMode
+	}{
+		{cacheDir, DirPerm},
+		{langDir, DirPerm},
+		{cachePath("cat", langDir), FilePerm},
+		{compressedCachePath("dog", langDir), FilePerm},
+	}
+
+	for _, tt := range tests {
+		info, err := os.Stat(tt.path)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := info.Mode().Perm(); got != tt.want {
+			t.Errorf("%s has mode %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package dict
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+const countsFileName = "counts.json"
+
+// countsMu serializes updates to the counts file, SearchAll records lookups
+// from several goroutines at once.
+var countsMu sync.Mutex
+
+type WordCount struct {
+	Word  string
+	Count int
+}
+
+func readCounts(countsPath string) (counts map[string]int, err error) {
+	counts = make(map[string]int)
+
+	rawJson, err := os.ReadFile(countsPath)
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return counts, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(rawJson, &counts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// RecordLookup adds one to the number of times word has been looked up,
+// counts are kept in a counts.json file in dir.
+func RecordLookup(dir, word string) error {
+	countsMu.Lock()
+	defer countsMu.Unlock()
+
+	unlock, err := lockDir(dir)
+
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	countsPath := filepath.Join(dir, countsFileName)
+
+	counts, err := readCounts(countsPath)
+
+	if err != nil {
+		return fmt.Errorf("Failed to read lookup counts: %w", err)
+	}
+
+	counts[NormalizeWord(word)]++
+
+	rawJson, err := json.Marshal(counts)
+
+	if err != nil {
+		return err
+	}
+
+	err = writeFileAtomic(countsPath, rawJson, FilePerm)
+
+	if err != nil {
+		return fmt.Errorf("Failed to write lookup counts: %w", err)
+	}
+
+	return nil
+}
+
+// MostFrequent returns the n most looked up words in dir, most frequent
+// first, n <= 0 returns every word.
+func MostFrequent(dir string, n int) ([]WordCount, error) {
+	countsMu.Lock()
+	counts, err := readCounts(filepath.Join(dir, countsFileName))
+	countsMu.Unlock()
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read lookup counts: %w", err)
+	}
+
+	words := make([]WordCount, 0, len(counts))
+
+	for word, count := range counts {
+		words = append(words, WordCount{Word: word, Count: count})
+	}
+
+	sort.Slice(words, func(i, j int) bool {
+		if words[i].Count != words[j].Count {
+			return words[i].Count > words[j].Count
+		}
+
+		return words[i].Word < words[j].Word
+	})
+
+	if n > 0 && len(words) > n {
+		words = words[:n]
+	}
+
+	return words, nil
+}
@@ -0,0 +1,323 @@
+// Package dict looks up words in the dictionaryapi.dev API and keeps the
+// responses in a local cache directory.
+package dict
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type WordInfo struct {
+	Word      string     `json:"word"`
+	Phonetic  string     `json:"phonetic"`
+	Phonetics []Phonetic `json:"phonetics"`
+	Origin    string     `json:"origin"`
+	Meanings  []Meaning  `json:"meanings"`
+	// SourceUrls are the web pages the entry was taken from.
+	SourceUrls []string `json:"sourceUrls,omitempty"`
+}
+
+type Phonetic struct {
+	Text  string `json:"text"`
+	Audio string `json:"audio,omitempty"`
+}
+
+type Meaning struct {
+	PartOfSpeech string       `json:"partOfSpeech"`
+	Definitions  []Definition `json:"definitions"`
+	Synonyms     []any        `json:"synonyms"`
+	Antonyms     []any        `json:"antonyms"`
+}
+
+type Definition struct {
+	Definition string `json:"definition"`
+	Example    string `json:"example"`
+	Synonyms   []any  `json:"synonyms"`
+	Antonyms   []any  `json:"antonyms"`
+}
+
+var ErrWordNotFound = errors.New("word not found")
+
+type WordNotFoundError struct {
+	Word string
+	// Details is the explanation given by the API, if any.
+	Details string
+}
+
+func (e *WordNotFoundError) Error() string {
+	if e.Details != "" {
+		return fmt.Sprintf("No definitions found for '%s'. %s", e.Word, e.Details)
+	}
+
+	return fmt.Sprintf("No definitions found for '%s'", e.Word)
+}
+
+func (e *WordNotFoundError) Is(target error) bool {
+	return target == ErrWordNotFound
+}
+
+// NoResultsError is returned when the API answers with no entries for a
+// word rather than a not found error.
+type NoResultsError struct {
+	Word string
+}
+
+func (e *NoResultsError) Error() string {
+	return fmt.Sprintf("No results for '%s'", e.Word)
+}
+
+type APIStatusError struct {
+	StatusCode int
+}
+
+func (e *APIStatusError) Error() string {
+	return fmt.Sprintf("dictionary API returned status %d", e.StatusCode)
+}
+
+// apiError is the body the API sends instead of a list of entries when a
+// lookup fails.
+type apiError struct {
+	Title      string `json:"title"`
+	Message    string `json:"message"`
+	Resolution string `json:"resolution"`
+}
+
+func (e *apiError) details() string {
+	return strings.TrimSpace(e.Message + " " + e.Resolution)
+}
+
+func (e *apiError) Error() string {
+	if e.Title == "" {
+		return e.details()
+	}
+
+	return e.Title + ": " + e.details()
+}
+
+func parseAPIError(rawJson []byte) *apiError {
+	var apiErr apiError
+
+	err := json.Unmarshal(rawJson, &apiErr)
+
+	if err != nil || (apiErr.Title == "" && apiErr.Message == "") {
+		return nil
+	}
+
+	return &apiErr
+}
+
+var ErrEmptyWord = errors.New("No word given")
+
+// ValidateWord rejects words that cannot be looked up, such as blank words
+// or words containing control characters.
+func ValidateWord(word string) error {
+	if strings.TrimSpace(word) == "" {
+		return ErrEmptyWord
+	}
+
+	if strings.ContainsFunc(word, unicode.IsControl) {
+		return fmt.Errorf("Word %q contains control characters", word)
+	}
+
+	return nil
+}
+
+// NormalizeWord returns the form of a word used for API requests and cache
+// file names, so that "Cat", "cat." and "'CAT'" share one cache entry.
+// Punctuation inside a word, as in "mother-in-law" or "don't", is kept.
+func NormalizeWord(word string) string {
+	word = strings.TrimFunc(word, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	})
+
+	return strings.ToLower(word)
+}
+
+type SearchOptions struct {
+	// Lang is the dictionary language code, empty means DefaultLanguage.
+	Lang string
+	// NoCache skips reading from and writing to the cache.
+	NoCache bool
+	// ReadOnly reads the cache but never writes to it.
+	ReadOnly bool
+	// Refresh skips reading from the cache and replaces the cached entry.
+	Refresh bool
+	// Offline never calls the API, words missing from the cache fail.
+	Offline bool
+	// CountsDir is where lookups are counted, empty disables counting.
+	CountsDir string
+	// AliasesDir holds the aliases resolved before a lookup, empty disables
+	// aliases.
+	AliasesDir string
+	// Provider is the dictionary service words are fetched from, nil means
+	// dictionaryapi.dev.
+	Provider Provider
+	// HistoryDir is where lookups are logged, empty disables the history.
+	HistoryDir string
+	// Metrics, when set, records cache hits and API fetches.
+	Metrics *Metrics
+}
+
+// Search looks a word up in cacheDir first and falls back to the API,
+// saving the API response to the cache.
+func Search(ctx context.Context, word, cacheDir string, opts SearchOptions) (parsed []WordInfo, err error) {
+	word = NormalizeWord(word)
+
+	if opts.AliasesDir != "" {
+		alias := word
+
+		word, err = ResolveAlias(opts.AliasesDir, alias)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if word != alias {
+			Logger.Debug("Resolved alias", "alias", alias, "word", word)
+		}
+	}
+
+	err = ValidateWord(word)
+
+	if err != nil {
+		return nil, err
+	}
+
+	lang := opts.Lang
+
+	if lang == "" {
+		lang = DefaultLanguage
+	}
+
+	var rawJson []byte
+
+	cached := false
+	expired := false
+
+	if !opts.NoCache && !opts.Refresh {
+		Logger.Debug("Checking cache", "word", word, "dir", cacheDir)
+
+		rawJson, err = FetchFromCache(word, cacheDir)
+		cached = err == nil
+		expired = errors.Is(err, ErrCacheExpired)
+
+		if cached {
+			err = json.Unmarshal(rawJson, &parsed)
+
+			if err != nil {
+				err = fmt.Errorf("%w: %w", ErrCorruptCache, err)
+				parsed = nil
+				cached = false
+			}
+		}
+
+		if cached {
+			Logger.Debug("Cache hit", "word", word, "bytes", len(rawJson))
+			opts.Metrics.recordHit()
+		} else if errors.Is(err, ErrCorruptCache) && !opts.ReadOnly {
+			Logger.Warn("Removing corrupted cache file", "word", word, "reason", err)
+			DeleteFromCache(word, cacheDir)
+		} else {
+			Logger.Debug("Cache miss", "word", word, "reason", err)
+		}
+	}
+
+	if !cached && opts.Offline {
+		return nil, fmt.Errorf("offline: '%s' not in cache", word)
+	}
+
+	negative := !opts.NoCache && NegativeTTL > 0
+
+	if !cached && negative && !opts.Refresh {
+		notFound := fetchNotFound(word, cacheDir)
+
+		if notFound != nil {
+			Logger.Debug("Word remembered as not found", "word", word)
+			return nil, fmt.Errorf("Failed to fetch word from API: %w", notFound)
+		}
+	}
+
+	var previous, validators Validators
+
+	if !cached && !opts.NoCache && !opts.ReadOnly && (expired || opts.Refresh) {
+		previous = cachedValidators(word, cacheDir)
+	}
+
+	if !cached {
+		rawJson, validators, err = fetchProvider(withMetrics(ctx, opts.Metrics), opts.Provider, word, lang, previous)
+
+		if errors.Is(err, ErrNotModified) {
+			Logger.Debug("Cached word not modified", "word", word)
+
+			rawJson, err = revalidateCache(word, cacheDir)
+			cached = err == nil
+		}
+
+		var notFound *WordNotFoundError
+
+		if negative && !opts.ReadOnly && errors.As(err, &notFound) {
+			saveErr := saveNotFound(word, cacheDir, notFound)
+
+			if saveErr != nil {
+				Logger.Debug("Not found word not remembered", "word", word, "reason", saveErr)
+			}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to fetch word from API: %w", err)
+		}
+
+		err = json.Unmarshal(rawJson, &parsed)
+
+		if err != nil {
+			apiErr := parseAPIError(rawJson)
+
+			if apiErr != nil {
+				return nil, fmt.Errorf("dictionary API error: %w", apiErr)
+			}
+
+			if !bytes.HasPrefix(bytes.TrimSpace(rawJson), []byte("[")) {
+				return nil, &NoResultsError{Word: word}
+			}
+
+			return nil, err
+		}
+	}
+
+	if len(parsed) == 0 {
+		return nil, &NoResultsError{Word: word}
+	}
+
+	if !opts.NoCache && !opts.ReadOnly && !cached {
+		err = saveToCache(word, rawJson, cacheDir, expired || opts.Refresh, validators)
+
+		if err != nil {
+			Logger.Debug("Word not saved to cache", "word", word, "reason", err)
+		}
+
+		removeNotFound(word, cacheDir)
+	}
+
+	if opts.CountsDir != "" {
+		err = RecordLookup(opts.CountsDir, word)
+
+		if err != nil {
+			Logger.Debug("Lookup not counted", "word", word, "reason", err)
+		}
+	}
+
+	if opts.HistoryDir != "" {
+		err = RecordHistory(opts.HistoryDir, word)
+
+		if err != nil {
+			Logger.Debug("Lookup not added to history", "word", word, "reason", err)
+		}
+	}
+
+	return parsed, nil
+}
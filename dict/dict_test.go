@@ -0,0 +1,402 @@
+package dict
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const catJson = `[{"word":"cat","phonetic":"/kæt/","meanings":[{"partOfSpeech":"noun","definitions":[{"definition":"A small domesticated feline."}]}]}]`
+
+// setForTest sets a package variable for the length of a test.
+func setForTest[T any](t *testing.T, v *T, value T) {
+	t.Helper()
+
+	old := *v
+	*v = value
+
+	t.Cleanup(func() {
+		*v = old
+	})
+}
+
+// testServer points BaseURL at handler for the length of a test, with rate
+// limiting off and retries that do not wait.
+func testServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	setForTest(t, &BaseURL, server.URL+"/")
+	setForTest(t, &Rate, 0)
+	setForTest(t, &RetryBackoff, time.Millisecond)
+
+	return server
+}
+
+func TestSearchReturnsFetchErrors(t *testing.T) {
+	server := testServer(t, func(w http.ResponseWriter, r *http.Request) {})
+	server.Close()
+
+	setForTest(t, &MaxAttempts, 1)
+
+	_, err := Search(context.Background(), "cat", t.TempDir(), SearchOptions{})
+
+	var urlErr *url.Error
+
+	if !errors.As(err, &urlErr) {
+		t.Fatalf("Search() error = %v, want the *url.Error of the failed request", err)
+	}
+
+	if !strings.HasPrefix(err.Error(), "Failed to fetch word from API") {
+		t.Errorf("Search() error = %q, want it to say the API fetch failed", err)
+	}
+}
+
+func TestSearchRefetchesExpiredWords(t *testing.T) {
+	const fetchedJson = `[{"word":"cat","meanings":[{"partOfSpeech":"verb","definitions":[{"definition":"To vomit."}]}]}]`
+
+	tests := []struct {
+		name         string
+		age          time.Duration
+		wantRequests int
+		wantCached   string
+	}{
+		{"fresh", time.Minute, 0, catJson},
+		{"expired", 2 * time.Hour, 1, fetchedJson},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+
+			testServer(t, func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Write([]byte(fetchedJson))
+			})
+
+			setForTest(t, &CacheTTL, time.Hour)
+
+			cacheDir := t.TempDir()
+
+			err := SaveToCache("cat", []byte(catJson), cacheDir, false)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			modTime := time.Now().Add(-tt.age)
+
+			err = os.Chtimes(cachePath("cat", cacheDir), modTime, modTime)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = Search(context.Background(), "cat", cacheDir, SearchOptions{})
+
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+
+			if requests != tt.wantRequests {
+				t.Errorf("API requests = %d, want %d", requests, tt.wantRequests)
+			}
+
+			setForTest(t, &CacheTTL, 0)
+
+			rawJson, err := FetchFromCache("cat", cacheDir)
+
+			if err != nil {
+				t.Fatalf("FetchFromCache() error = %v", err)
+			}
+
+			if string(rawJson) != tt.wantCached {
+				t.Errorf("cached = %s, want %s", rawJson, tt.wantCached)
+			}
+		})
+	}
+}
+
+func TestSearchNoCache(t *testing.T) {
+	tests := []struct {
+		name      string
+		noCache   bool
+		wantFiles []string
+	}{
+		{"cache", false, []string{"cat.json"}},
+		{"no cache", true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(catJson))
+			})
+
+			cacheDir := t.TempDir()
+
+			_, err := Search(context.Background(), "cat", cacheDir, SearchOptions{NoCache: tt.noCache})
+
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+
+			if got := fileNames(t, cacheDir); !slices.Equal(got, tt.wantFiles) {
+				t.Errorf("files in cache directory = %v, want %v", got, tt.wantFiles)
+			}
+		})
+	}
+}
+
+func TestSearchNormalizesCase(t *testing.T) {
+	var paths []string
+
+	testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(catJson))
+	})
+
+	cacheDir := t.TempDir()
+
+	for _, word := range []string{"Cat", "cat", "CAT"} {
+		_, err := Search(context.Background(), word, cacheDir, SearchOptions{})
+
+		if err != nil {
+			t.Fatalf("Search(%q) error = %v", word, err)
+		}
+	}
+
+	if want := []string{"/en/cat"}; !slices.Equal(paths, want) {
+		t.Errorf("API requests = %v, want %v", paths, want)
+	}
+
+	if got, want := fileNames(t, cacheDir), []string{"cat.json"}; !slices.Equal(got, want) {
+		t.Errorf("files in cache directory = %v, want %v", got, want)
+	}
+}
+
+func TestSearchRejectsEmptyResponses(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"empty array", `[]`},
+		{"empty array with whitespace", " [ ]\n"},
+		{"empty object", `{}`},
+		{"string", `"cat"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			})
+
+			cacheDir := t.TempDir()
+			dataDir := t.TempDir()
+
+			_, err := Search(context.Background(), "cat", cacheDir, SearchOptions{CountsDir: dataDir, HistoryDir: dataDir})
+
+			var noResults *NoResultsError
+
+			if !errors.As(err, &noResults) || err.Error() != "No results for 'cat'" {
+				t.Fatalf("Search() error = %v, want No results for 'cat'", err)
+			}
+
+			if got := fileNames(t, cacheDir); len(got) > 0 {
+				t.Errorf("files in cache directory = %v, want none", got)
+			}
+
+			if got := fileNames(t, dataDir); len(got) > 0 {
+				t.Errorf("files in data directory = %v, want none", got)
+			}
+		})
+	}
+}
+
+func TestSearchOffline(t *testing.T) {
+	tests := []struct {
+		name    string
+		cached  bool
+		wantErr string
+	}{
+		{"cached", true, ""},
+		{"not cached", false, "offline: 'cat' not in cache"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testServer(t, func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("offline lookup requested %s", r.URL.Path)
+			})
+
+			cacheDir := t.TempDir()
+
+			if tt.cached {
+				err := SaveToCache("cat", []byte(catJson), cacheDir, false)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			entries, err := Search(context.Background(), "cat", cacheDir, SearchOptions{Offline: true})
+
+			if tt.wantErr == "" {
+				if err != nil || len(entries) != 1 {
+					t.Errorf("Search() = %v, %v, want the cached entry", entries, err)
+				}
+
+				return
+			}
+
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Search() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizeWord(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"cat", "cat"},
+		{"Cat", "cat"},
+		{"  cat\t\n", "cat"},
+		{"cat.", "cat"},
+		{"'cat'", "cat"},
+		{`"Cat!"`, "cat"},
+		{"(cat),", "cat"},
+		{"mother-in-law", "mother-in-law"},
+		{"don't", "don't"},
+		{"...", ""},
+		{"ice cream", "ice cream"},
+		{"«Café»", "café"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeWord(tt.word); got != tt.want {
+			t.Errorf("NormalizeWord(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestSearchRecoversFromCorruptCache(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		readOnly  bool
+		wantCache bool
+	}{
+		{"garbage", "not json at all", false, true},
+		{"truncated", catJson[:20], false, true},
+		{"truncated entry", `{"schemaVersion":1,"raw":[{"word"`, false, true},
+		{"read only", "not json at all", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+
+			testServer(t, func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Write([]byte(catJson))
+			})
+
+			cacheDir := t.TempDir()
+			writeFiles(t, cacheDir, map[string]string{"cat.json": tt.file})
+
+			entries, err := Search(context.Background(), "cat", cacheDir, SearchOptions{ReadOnly: tt.readOnly})
+
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+
+			if len(entries) != 1 || entries[0].Word != "cat" || requests != 1 {
+				t.Errorf("Search() = %v after %d requests, want cat from the API", entries, requests)
+			}
+
+			rawJson, err := FetchFromCache("cat", cacheDir)
+
+			if tt.wantCache && string(rawJson) != catJson {
+				t.Errorf("cached = %s, %v, want the fetched response", rawJson, err)
+			}
+
+			if !tt.wantCache && !errors.Is(err, ErrCorruptCache) {
+				t.Errorf("FetchFromCache() error = %v, want the corrupt file left alone", err)
+			}
+		})
+	}
+}
+
+func TestSearchCountsLookups(t *testing.T) {
+	tests := []struct {
+		name      string
+		cacheDir  string
+		opts      SearchOptions
+		noCounts  bool
+		wantCount int
+	}{
+		{"cache", "", SearchOptions{}, false, 2},
+		{"no cache", "", SearchOptions{NoCache: true}, false, 2},
+		{"read-only cache", "", SearchOptions{ReadOnly: true}, false, 2},
+		{"unusable cache directory", "/dev/null/cache", SearchOptions{}, false, 2},
+		{"counting disabled", "", SearchOptions{}, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(catJson))
+			})
+
+			cacheDir := tt.cacheDir
+
+			if cacheDir == "" {
+				cacheDir = t.TempDir()
+			}
+
+			countsDir := t.TempDir()
+			opts := tt.opts
+
+			if !tt.noCounts {
+				opts.CountsDir = countsDir
+			}
+
+			for range 2 {
+				_, err := Search(context.Background(), "cat", cacheDir, opts)
+
+				if err != nil {
+					t.Fatalf("Search() error = %v", err)
+				}
+			}
+
+			counts, err := MostFrequent(countsDir, 1)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := 0
+
+			if len(counts) > 0 {
+				got = counts[0].Count
+			}
+
+			if got != tt.wantCount {
+				t.Errorf("cat counted %d times, want %d", got, tt.wantCount)
+			}
+		})
+	}
+}
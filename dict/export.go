@@ -0,0 +1,70 @@
+package dict
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ExportCache returns the cached API response of every word keyed by word.
+func ExportCache(cacheDir string) (map[string]json.RawMessage, error) {
+	words, err := CachedWords(cacheDir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]json.RawMessage, len(words))
+
+	for _, word := range words {
+		rawJson, err := readCachedWord(word, cacheDir)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read cached word %s: %w", word, err)
+		}
+
+		entries[word] = rawJson
+	}
+
+	return entries, nil
+}
+
+// ImportCache saves each entry to the cache under its normalized word.
+// Words that are already cached are skipped unless overwrite is set, entries
+// whose key is not a valid word are always skipped.
+func ImportCache(cacheDir string, entries map[string]json.RawMessage, overwrite bool) (imported, skipped, invalid int, err error) {
+	for key, rawJson := range entries {
+		word := NormalizeWord(key)
+
+		err = ValidateWord(word)
+
+		if err != nil {
+			Logger.Debug("Skipping invalid word in import", "word", key, "reason", err)
+			invalid++
+			continue
+		}
+
+		var parsed []WordInfo
+
+		err = json.Unmarshal(rawJson, &parsed)
+
+		if err != nil {
+			return imported, skipped, invalid, fmt.Errorf("Invalid entry for word %s: %w", word, err)
+		}
+
+		err = SaveToCache(word, rawJson, cacheDir, overwrite)
+
+		if errors.Is(err, ErrAlreadyCached) {
+			skipped++
+			continue
+		}
+
+		if err != nil {
+			return imported, skipped, invalid, err
+		}
+
+		imported++
+	}
+
+	return imported, skipped, invalid, nil
+}
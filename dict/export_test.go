@@ -0,0 +1,51 @@
+package dict
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestImportCache(t *testing.T) {
+	tests := []struct {
+		name         string
+		entries      map[string]json.RawMessage
+		wantImported int
+		wantInvalid  int
+		wantFiles    []string
+	}{
+		{
+			name:         "normalizes words",
+			entries:      map[string]json.RawMessage{"Cat.": json.RawMessage(catJson), " DOG ": json.RawMessage(`[]`)},
+			wantImported: 2,
+			wantFiles:    []string{"cat.json", "dog.json"},
+		},
+		{
+			name:         "skips invalid words",
+			entries:      map[string]json.RawMessage{"cat": json.RawMessage(catJson), "": json.RawMessage(`[]`), "...": json.RawMessage(`[]`), "c\x00t": json.RawMessage(`[]`)},
+			wantImported: 1,
+			wantInvalid:  3,
+			wantFiles:    []string{"cat.json"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheDir := t.TempDir()
+
+			imported, skipped, invalid, err := ImportCache(cacheDir, tt.entries, false)
+
+			if err != nil {
+				t.Fatalf("ImportCache() error = %v", err)
+			}
+
+			if imported != tt.wantImported || skipped != 0 || invalid != tt.wantInvalid {
+				t.Errorf("ImportCache() = %d imported, %d skipped, %d invalid, want %d, 0, %d", imported, skipped, invalid, tt.wantImported, tt.wantInvalid)
+			}
+
+			if got := fileNames(t, cacheDir); !slices.Equal(got, tt.wantFiles) {
+				t.Errorf("files in cache directory = %v, want %v", got, tt.wantFiles)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package dict
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+const favoritesFileName = "favorites.json"
+
+// Favorites returns the starred words in dir, sorted.
+func Favorites(dir string) (words []string, err error) {
+	rawJson, err := os.ReadFile(filepath.Join(dir, favoritesFileName))
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read favorites: %w", err)
+	}
+
+	err = json.Unmarshal(rawJson, &words)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse favorites: %w", err)
+	}
+
+	slices.Sort(words)
+
+	return words, nil
+}
+
+func saveFavorites(dir string, words []string) error {
+	slices.Sort(words)
+
+	rawJson, err := json.MarshalIndent(words, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	err = writeFileAtomic(filepath.Join(dir, favoritesFileName), rawJson, FilePerm)
+
+	if err != nil {
+		return fmt.Errorf("Failed to write favorites: %w", err)
+	}
+
+	return nil
+}
+
+// Star adds word to the favorites in dir, added is false when it was
+// already starred.
+func Star(dir, word string) (added bool, err error) {
+	word = NormalizeWord(word)
+
+	unlock, err := lockDir(dir)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer unlock()
+
+	words, err := Favorites(dir)
+
+	if err != nil || slices.Contains(words, word) {
+		return false, err
+	}
+
+	return true, saveFavorites(dir, append(words, word))
+}
+
+// Unstar removes word from the favorites in dir, removed is false when it
+// was not starred.
+func Unstar(dir, word string) (removed bool, err error) {
+	word = NormalizeWord(word)
+
+	unlock, err := lockDir(dir)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer unlock()
+
+	words, err := Favorites(dir)
+
+	if err != nil || !slices.Contains(words, word) {
+		return false, err
+	}
+
+	words = slices.DeleteFunc(words, func(w string) bool {
+		return w == word
+	})
+
+	return true, saveFavorites(dir, words)
+}
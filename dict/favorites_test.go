@@ -0,0 +1,63 @@
+package dict
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStarAndUnstar(t *testing.T) {
+	dir := t.TempDir()
+
+	steps := []struct {
+		name        string
+		star        bool
+		word        string
+		wantChanged bool
+		want        []string
+	}{
+		{"star", true, "zebra", true, []string{"zebra"}},
+		{"star another", true, "Apple", true, []string{"apple", "zebra"}},
+		{"star again", true, "apple.", false, []string{"apple", "zebra"}},
+		{"unstar", false, "ZEBRA", true, []string{"apple"}},
+		{"unstar again", false, "zebra", false, []string{"apple"}},
+		{"unstar never starred", false, "cat", false, []string{"apple"}},
+		{"unstar last", false, "apple", true, []string{}},
+	}
+
+	for _, step := range steps {
+		var changed bool
+		var err error
+
+		if step.star {
+			changed, err = Star(dir, step.word)
+		} else {
+			changed, err = Unstar(dir, step.word)
+		}
+
+		if err != nil {
+			t.Fatalf("%s: error = %v", step.name, err)
+		}
+
+		if changed != step.wantChanged {
+			t.Errorf("%s: changed = %v, want %v", step.name, changed, step.wantChanged)
+		}
+
+		words, err := Favorites(dir)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(words, step.want) {
+			t.Errorf("%s: Favorites() = %q, want %q", step.name, words, step.want)
+		}
+	}
+}
+
+func TestFavoritesMissingFile(t *testing.T) {
+	words, err := Favorites(t.TempDir())
+
+	if err != nil || len(words) > 0 {
+		t.Errorf("Favorites() = %q, %v, want none", words, err)
+	}
+}
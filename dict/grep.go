@@ -0,0 +1,51 @@
+package dict
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type Match struct {
+	Word       string
+	Definition string
+}
+
+// GrepCache returns every cached definition containing term, ignoring case.
+func GrepCache(cacheDir, term string) (matches []Match, err error) {
+	words, err := CachedWords(cacheDir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	term = strings.ToLower(term)
+
+	for _, word := range words {
+		rawJson, err := readCachedWord(word, cacheDir)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read cached word %s: %w", word, err)
+		}
+
+		var parsed []WordInfo
+
+		err = json.Unmarshal(rawJson, &parsed)
+
+		if err != nil {
+			continue
+		}
+
+		for _, wordInfo := range parsed {
+			for _, m := range wordInfo.Meanings {
+				for _, d := range m.Definitions {
+					if strings.Contains(strings.ToLower(d.Definition), term) {
+						matches = append(matches, Match{Word: word, Definition: d.Definition})
+					}
+				}
+			}
+		}
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,161 @@
+package dict
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const historyFileName = "history.log"
+
+const DefaultHistoryMax = 1000
+
+// HistoryMax is the most lookups kept in the history log, older lookups are
+// trimmed when it grows beyond it. Zero keeps every lookup.
+var HistoryMax = DefaultHistoryMax
+
+// historyMu serializes updates to the history log, SearchAll records lookups
+// from several goroutines at once.
+var historyMu sync.Mutex
+
+type HistoryEntry struct {
+	Word string    `json:"word"`
+	Time time.Time `json:"time"`
+}
+
+// readHistoryLines returns the lines of the history log, skipping blank
+// lines left by an interrupted write.
+func readHistoryLines(historyPath string) (lines [][]byte, err error) {
+	data, err := os.ReadFile(historyPath)
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+
+		if len(line) > 0 {
+			lines = append(lines, bytes.Clone(line))
+		}
+	}
+
+	return lines, scanner.Err()
+}
+
+// RecordHistory appends a lookup of word to the history.log file in dir,
+// one JSON object per line.
+func RecordHistory(dir, word string) error {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	unlock, err := lockDir(dir)
+
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	historyPath := filepath.Join(dir, historyFileName)
+
+	line, err := json.Marshal(HistoryEntry{Word: NormalizeWord(word), Time: time.Now()})
+
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, FilePerm)
+
+	if err != nil {
+		return fmt.Errorf("Failed to open history log: %w", err)
+	}
+
+	_, err = file.Write(append(line, '\n'))
+
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("Failed to write history log: %w", err)
+	}
+
+	err = file.Close()
+
+	if err != nil {
+		return fmt.Errorf("Failed to write history log: %w", err)
+	}
+
+	if HistoryMax > 0 {
+		return trimHistory(historyPath, HistoryMax)
+	}
+
+	return nil
+}
+
+// trimHistory keeps only the last max lines of the history log.
+func trimHistory(historyPath string, max int) error {
+	lines, err := readHistoryLines(historyPath)
+
+	if err != nil {
+		return fmt.Errorf("Failed to read history log: %w", err)
+	}
+
+	if len(lines) <= max {
+		return nil
+	}
+
+	data := append(bytes.Join(lines[len(lines)-max:], []byte("\n")), '\n')
+
+	err = writeFileAtomic(historyPath, data, FilePerm)
+
+	if err != nil {
+		return fmt.Errorf("Failed to trim history log: %w", err)
+	}
+
+	return nil
+}
+
+// History returns the last n lookups recorded in dir, oldest first, n <= 0
+// returns every lookup. Lines that cannot be decoded are skipped.
+func History(dir string, n int) ([]HistoryEntry, error) {
+	historyMu.Lock()
+	lines, err := readHistoryLines(filepath.Join(dir, historyFileName))
+	historyMu.Unlock()
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read history log: %w", err)
+	}
+
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+
+	entries := make([]HistoryEntry, 0, len(lines))
+
+	for _, line := range lines {
+		var entry HistoryEntry
+
+		err = json.Unmarshal(line, &entry)
+
+		if err != nil {
+			Logger.Debug("Skipping history line", "line", string(line), "reason", err)
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
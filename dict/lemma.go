@@ -0,0 +1,62 @@
+package dict
+
+import (
+	"slices"
+	"strings"
+)
+
+// Lemmas returns likely base forms of an inflected word, most likely first,
+// e.g. "run" for "running" or "cat" for "cats". It only strips common
+// English suffixes, so some of the forms are not real words.
+func Lemmas(word string) []string {
+	word = NormalizeWord(word)
+
+	var lemmas []string
+
+	add := func(lemma string) {
+		if len(lemma) > 1 && lemma != word && !slices.Contains(lemmas, lemma) {
+			lemmas = append(lemmas, lemma)
+		}
+	}
+
+	addStem := func(stem string) {
+		if hasDoubleConsonant(stem) {
+			add(stem[:len(stem)-1])
+		}
+
+		add(stem)
+		add(stem + "e")
+	}
+
+	switch {
+	case strings.HasSuffix(word, "ies"):
+		add(strings.TrimSuffix(word, "ies") + "y")
+		add(strings.TrimSuffix(word, "s"))
+	case strings.HasSuffix(word, "es"):
+		add(strings.TrimSuffix(word, "s"))
+		add(strings.TrimSuffix(word, "es"))
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		add(strings.TrimSuffix(word, "s"))
+	case strings.HasSuffix(word, "ing"):
+		addStem(strings.TrimSuffix(word, "ing"))
+	case strings.HasSuffix(word, "ied"):
+		add(strings.TrimSuffix(word, "ied") + "y")
+	case strings.HasSuffix(word, "ed"):
+		addStem(strings.TrimSuffix(word, "ed"))
+		add(strings.TrimSuffix(word, "d"))
+	}
+
+	return lemmas
+}
+
+// hasDoubleConsonant reports whether a word ends in a doubled consonant, as
+// the "nn" left over from "running".
+func hasDoubleConsonant(word string) bool {
+	n := len(word)
+
+	if n < 2 || word[n-1] != word[n-2] {
+		return false
+	}
+
+	return !strings.ContainsRune("aeiouy", rune(word[n-1]))
+}
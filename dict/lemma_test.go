@@ -0,0 +1,33 @@
+package dict
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLemmas(t *testing.T) {
+	tests := []struct {
+		word string
+		want []string
+	}{
+		{"cats", []string{"cat"}},
+		{"Cats", []string{"cat"}},
+		{"boxes", []string{"boxe", "box"}},
+		{"flies", []string{"fly", "flie"}},
+		{"running", []string{"run", "runn", "runne"}},
+		{"making", []string{"mak", "make"}},
+		{"walked", []string{"walk", "walke"}},
+		{"stopped", []string{"stop", "stopp", "stoppe"}},
+		{"baked", []string{"bak", "bake"}},
+		{"carried", []string{"carry"}},
+		{"glass", nil},
+		{"cat", nil},
+		{"is", nil},
+	}
+
+	for _, tt := range tests {
+		if got := Lemmas(tt.word); !slices.Equal(got, tt.want) {
+			t.Errorf("Lemmas(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package dict
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const lockFileName = ".wordef.lock"
+
+// lockDir takes an exclusive lock on dir shared by every wordef process and
+// holds it until unlock is called. Only writers lock, readers never block
+// since every file is replaced atomically.
+func lockDir(dir string) (unlock func(), err error) {
+	file, err := os.OpenFile(filepath.Join(dir, lockFileName), os.O_CREATE|os.O_RDWR, FilePerm)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open lock file: %w", err)
+	}
+
+	err = lockFile(file)
+
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("Failed to lock %s: %w", dir, err)
+	}
+
+	return func() {
+		unlockFile(file)
+		file.Close()
+	}, nil
+}
@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+package dict
+
+import "os"
+
+// Other systems have no file locks, concurrent processes may then lose an
+// update but never leave a partial file.
+func lockFile(file *os.File) error {
+	return nil
+}
+
+func unlockFile(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,177 @@
+package dict
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const (
+	writerProcesses = 8
+	writerRounds    = 25
+)
+
+// TestWriterProcess is not a real test, TestConcurrentWriters runs the test
+// binary again with WORDEF_TEST_WRITER set to write from a separate process.
+func TestWriterProcess(t *testing.T) {
+	op := os.Getenv("WORDEF_TEST_WRITER")
+
+	if op == "" {
+		t.Skip("only runs as a writer process for TestConcurrentWriters")
+	}
+
+	dir := os.Getenv("WORDEF_TEST_DIR")
+	id := os.Getenv("WORDEF_TEST_ID")
+	HistoryMax, _ = strconv.Atoi(os.Getenv("WORDEF_TEST_HISTORY_MAX"))
+
+	for i := range writerRounds {
+		var err error
+
+		switch op {
+		case "save":
+			body := strings.Replace(catJson, "A small domesticated feline.", fmt.Sprintf("Written by %s in round %d.", id, i), 1)
+			err = SaveToCache("cat", []byte(body), dir, true)
+		case "count":
+			err = RecordLookup(dir, "cat")
+		case "history":
+			err = RecordHistory(dir, "cat")
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestConcurrentWriters(t *testing.T) {
+	tests := []struct {
+		name       string
+		op         string
+		historyMax int
+		check      func(t *testing.T, dir string)
+	}{
+		{
+			name: "same cache word",
+			op:   "save",
+			check: func(t *testing.T, dir string) {
+				rawJson, err := FetchFromCache("cat", dir)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var words []WordInfo
+
+				err = json.Unmarshal(rawJson, &words)
+
+				if err != nil || len(words) != 1 || !strings.HasPrefix(words[0].Meanings[0].Definitions[0].Definition, "Written by") {
+					t.Errorf("cached cat is corrupt: %s", rawJson)
+				}
+			},
+		},
+		{
+			name: "lookup counts",
+			op:   "count",
+			check: func(t *testing.T, dir string) {
+				counts, err := readCounts(filepath.Join(dir, countsFileName))
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if counts["cat"] != writerProcesses*writerRounds {
+					t.Errorf("cat counted %d times, want %d", counts["cat"], writerProcesses*writerRounds)
+				}
+			},
+		},
+		{
+			name: "history log",
+			op:   "history",
+			check: func(t *testing.T, dir string) {
+				entries, err := History(dir, 0)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if len(entries) != writerProcesses*writerRounds {
+					t.Errorf("got %d history entries, want %d", len(entries), writerProcesses*writerRounds)
+				}
+			},
+		},
+		{
+			name:       "trimmed history log",
+			op:         "history",
+			historyMax: 50,
+			check: func(t *testing.T, dir string) {
+				lines, err := readHistoryLines(filepath.Join(dir, historyFileName))
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if len(lines) != 50 {
+					t.Errorf("got %d history lines, want 50", len(lines))
+				}
+
+				for _, line := range lines {
+					var entry HistoryEntry
+
+					if json.Unmarshal(line, &entry) != nil || entry.Word != "cat" {
+						t.Errorf("corrupt history line %q", line)
+					}
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writers := make([]*exec.Cmd, writerProcesses)
+			output := make([]bytes.Buffer, writerProcesses)
+
+			for i := range writers {
+				cmd := exec.Command(os.Args[0], "-test.run=^TestWriterProcess$")
+				cmd.Env = append(os.Environ(),
+					"WORDEF_TEST_WRITER="+tt.op,
+					"WORDEF_TEST_DIR="+dir,
+					"WORDEF_TEST_ID="+strconv.Itoa(i),
+					"WORDEF_TEST_HISTORY_MAX="+strconv.Itoa(tt.historyMax),
+				)
+				cmd.Stdout = &output[i]
+				cmd.Stderr = &output[i]
+
+				err := cmd.Start()
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				writers[i] = cmd
+			}
+
+			for i, cmd := range writers {
+				err := cmd.Wait()
+
+				if err != nil {
+					t.Errorf("writer process failed: %v\n%s", err, output[i].String())
+				}
+			}
+
+			tt.check(t, dir)
+
+			for _, name := range fileNames(t, dir) {
+				if strings.HasSuffix(name, ".tmp") {
+					t.Errorf("temporary file %s left behind", name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package dict
+
+import "log/slog"
+
+// Logger receives debug messages about cache and API activity, it discards
+// everything unless replaced.
+var Logger = slog.New(slog.DiscardHandler)
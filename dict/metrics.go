@@ -0,0 +1,48 @@
+package dict
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Metrics counts where the words looked up by Search came from. It is safe
+// to share between the goroutines of SearchAll.
+type Metrics struct {
+	CacheHits atomic.Int64
+	// APIFetches and BytesDownloaded count the response bodies received,
+	// failed requests and unchanged cached words are not fetches.
+	APIFetches      atomic.Int64
+	BytesDownloaded atomic.Int64
+}
+
+func (m *Metrics) recordHit() {
+	if m != nil {
+		m.CacheHits.Add(1)
+	}
+}
+
+func (m *Metrics) recordFetch(bytes int) {
+	if m != nil {
+		m.APIFetches.Add(1)
+		m.BytesDownloaded.Add(int64(bytes))
+	}
+}
+
+type metricsKey struct{}
+
+// withMetrics returns a context carrying m, providers record the response
+// bodies they receive in it.
+func withMetrics(ctx context.Context, m *Metrics) context.Context {
+	if m == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, metricsKey{}, m)
+}
+
+// metricsFrom returns the Metrics carried by ctx, or nil.
+func metricsFrom(ctx context.Context) *Metrics {
+	m, _ := ctx.Value(metricsKey{}).(*Metrics)
+
+	return m
+}
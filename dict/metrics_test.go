@@ -0,0 +1,115 @@
+package dict
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSearchMetrics(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		cached      bool
+		expired     bool
+		timeout     bool
+		wantHits    int64
+		wantFetches int64
+		wantBytes   int64
+	}{
+		{name: "fetched", status: http.StatusOK, wantFetches: 1, wantBytes: int64(len(catJson))},
+		{name: "cache hit", status: http.StatusOK, cached: true, wantHits: 1},
+		{name: "not modified", status: http.StatusNotModified, cached: true, expired: true},
+		{name: "not found", status: http.StatusNotFound},
+		{name: "server error", status: http.StatusInternalServerError},
+		{name: "timeout", status: http.StatusOK, timeout: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if tt.timeout {
+					time.Sleep(50 * time.Millisecond)
+				}
+
+				if tt.status != http.StatusOK {
+					w.WriteHeader(tt.status)
+					return
+				}
+
+				w.Header().Set("ETag", `"v1"`)
+				w.Write([]byte(catJson))
+			})
+
+			setForTest(t, &CacheTTL, time.Hour)
+
+			if tt.timeout {
+				setForTest(t, &HTTPClient.Timeout, 10*time.Millisecond)
+			}
+
+			cacheDir := t.TempDir()
+
+			if tt.cached {
+				err := saveToCache("cat", []byte(catJson), cacheDir, false, Validators{ETag: `"v1"`})
+
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if tt.expired {
+				old := time.Now().Add(-2 * time.Hour)
+
+				err := os.Chtimes(cachePath("cat", cacheDir), old, old)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			metrics := &Metrics{}
+
+			Search(context.Background(), "cat", cacheDir, SearchOptions{Metrics: metrics})
+
+			if got := metrics.CacheHits.Load(); got != tt.wantHits {
+				t.Errorf("CacheHits = %d, want %d", got, tt.wantHits)
+			}
+
+			if got := metrics.APIFetches.Load(); got != tt.wantFetches {
+				t.Errorf("APIFetches = %d, want %d", got, tt.wantFetches)
+			}
+
+			if got := metrics.BytesDownloaded.Load(); got != tt.wantBytes {
+				t.Errorf("BytesDownloaded = %d, want %d", got, tt.wantBytes)
+			}
+		})
+	}
+}
+
+func TestWordsAPIMetrics(t *testing.T) {
+	const body = `{"word":"cat","results":[{"definition":"feline mammal","partOfSpeech":"noun"}],"frequency":4.5}`
+
+	server := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	})
+
+	setForTest(t, &WordsAPIURL, server.URL+"/words/")
+
+	metrics := &Metrics{}
+
+	_, err := Search(context.Background(), "cat", t.TempDir(), SearchOptions{Provider: &WordsAPI{Key: "secret"}, Metrics: metrics})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := metrics.APIFetches.Load(); got != 1 {
+		t.Errorf("APIFetches = %d, want 1", got)
+	}
+
+	if got := metrics.BytesDownloaded.Load(); got != int64(len(body)) {
+		t.Errorf("BytesDownloaded = %d, want the %d bytes of the response", got, len(body))
+	}
+}
@@ -0,0 +1,378 @@
+package dict
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// migratedFileName marks a cache directory the old cache was already moved
+// into, so the migration runs only once.
+const migratedFileName = ".wordef-migrated"
+
+// MigrateCache moves the language directories older versions of wordef
+// kept in oldDir, the config directory, to cacheDir. Words saved directly in
+// oldDir move into the DefaultLanguage directory of cacheDir, the other
+// files there, such as the aliases and the config file, stay where they are.
+// Words already in cacheDir are kept and their old copies left behind. It
+// only runs once per cacheDir and is meant for the default directories only,
+// never for a directory the user chose.
+func MigrateCache(oldDir, cacheDir string) (moved int, err error) {
+	if oldDir == cacheDir {
+		return 0, nil
+	}
+
+	markerPath := filepath.Join(cacheDir, migratedFileName)
+
+	_, err = os.Stat(markerPath)
+
+	if err == nil {
+		return 0, nil
+	}
+
+	entries, err := os.ReadDir(oldDir)
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("Failed to read old cache directory: %w", err)
+	}
+
+	unlock, err := lockDir(cacheDir)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer unlock()
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		n, err := moveDir(filepath.Join(oldDir, entry.Name()), filepath.Join(cacheDir, entry.Name()))
+		moved += n
+
+		if err != nil {
+			return moved, fmt.Errorf("Failed to move cache to %s: %w", cacheDir, err)
+		}
+	}
+
+	langDir := filepath.Join(cacheDir, DefaultLanguage)
+
+	err = os.MkdirAll(langDir, DirPerm)
+
+	if err != nil {
+		return moved, fmt.Errorf("Failed to create language directory: %w", err)
+	}
+
+	n, err := moveRootWords(oldDir, langDir)
+	moved += n
+
+	if err != nil {
+		return moved, fmt.Errorf("Failed to move saved words to %s: %w", langDir, err)
+	}
+
+	err = os.WriteFile(markerPath, nil, FilePerm)
+
+	if err != nil {
+		return moved, fmt.Errorf("Failed to mark cache as moved: %w", err)
+	}
+
+	return moved, nil
+}
+
+// metadataFiles are the files kept next to the saved words that are not
+// words themselves.
+var metadataFiles = []string{"config.json", aliasesFileName, countsFileName, favoritesFileName}
+
+// moveRootWords moves the word files directly in src into dst, renaming
+// them to the lowercased names they are saved under now. Only files holding
+// a saved word are moved, other JSON files stay. Words already in dst are
+// kept and their old copies left behind.
+func moveRootWords(src, dst string) (moved int, err error) {
+	entries, err := os.ReadDir(src)
+
+	if err != nil {
+		return 0, err
+	}
+
+	var words []string
+
+	for _, entry := range entries {
+		if entry.IsDir() || slices.Contains(metadataFiles, entry.Name()) {
+			continue
+		}
+
+		_, ok := cachedWordName(entry.Name())
+
+		if ok && isCacheFile(filepath.Join(src, entry.Name())) {
+			words = append(words, entry.Name())
+		}
+	}
+
+	if len(words) == 0 {
+		return 0, nil
+	}
+
+	unlock, err := lockDir(dst)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer unlock()
+
+	for _, name := range words {
+		word, _ := cachedWordName(name)
+
+		dstPath := cachePath(word, dst)
+
+		if strings.HasSuffix(name, compressedCacheExt) {
+			dstPath = compressedCachePath(word, dst)
+		}
+
+		if IsCached(word, dst) {
+			continue
+		}
+
+		err = os.Rename(filepath.Join(src, name), dstPath)
+
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			return moved, err
+		}
+
+		moved++
+	}
+
+	return moved, nil
+}
+
+// isCacheFile reports whether the file at path holds a saved word, in
+// either the current or the bare format.
+func isCacheFile(path string) bool {
+	data, err := readCacheFile(path)
+
+	if err != nil {
+		return false
+	}
+
+	entry, err := decodeCacheFile(data)
+
+	if err != nil {
+		return false
+	}
+
+	var words []WordInfo
+
+	err = json.Unmarshal(entry.Raw, &words)
+
+	if err != nil || len(words) == 0 {
+		return false
+	}
+
+	for _, word := range words {
+		if word.Word == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// moveDir moves the files of src into dst, files already in dst win. src is
+// removed once it is empty.
+func moveDir(src, dst string) (moved int, err error) {
+	_, err = os.Lstat(dst)
+
+	if errors.Is(err, fs.ErrNotExist) {
+		err = os.Rename(src, dst)
+
+		if err != nil {
+			return 0, err
+		}
+
+		err = filepath.WalkDir(dst, func(s string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				moved++
+			}
+
+			return err
+		})
+
+		return moved, err
+	}
+
+	entries, err := os.ReadDir(src)
+
+	if err != nil {
+		return 0, err
+	}
+
+	for _, entry := range entries {
+		srcPath := filepath.Join(src, entry.Name())
+		dstPath := filepath.Join(dst, entry.Name())
+
+		if entry.IsDir() {
+			n, err := moveDir(srcPath, dstPath)
+			moved += n
+
+			if err != nil {
+				return moved, err
+			}
+
+			continue
+		}
+
+		_, err = os.Lstat(dstPath)
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		err = os.Rename(srcPath, dstPath)
+
+		if err != nil {
+			return moved, err
+		}
+
+		moved++
+	}
+
+	os.Remove(src)
+
+	return moved, nil
+}
+
+// dataFiles are the files older versions of wordef kept in the cache
+// directory and that now live in the config directory, each with a check
+// that the file holds what wordef writes there.
+var dataFiles = map[string]func(path string) bool{
+	aliasesFileName:   isJsonFile[map[string]string],
+	favoritesFileName: isJsonFile[[]string],
+	countsFileName:    isJsonFile[map[string]int],
+	historyFileName:   isHistoryLog,
+}
+
+// MigrateData moves the aliases, favorites, lookup counts and history that
+// older versions of wordef kept in oldDir, the cache directory, to dataDir.
+// Files already in dataDir are kept and their old copies left behind.
+func MigrateData(oldDir, dataDir string) (moved int, err error) {
+	if oldDir == dataDir {
+		return 0, nil
+	}
+
+	var names []string
+
+	for name, valid := range dataFiles {
+		if valid(filepath.Join(oldDir, name)) {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return 0, nil
+	}
+
+	unlock, err := lockDir(dataDir)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer unlock()
+
+	for _, name := range names {
+		dstPath := filepath.Join(dataDir, name)
+
+		_, err = os.Lstat(dstPath)
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		err = moveFile(filepath.Join(oldDir, name), dstPath)
+
+		if err != nil {
+			return moved, fmt.Errorf("Failed to move %s to %s: %w", name, dataDir, err)
+		}
+
+		moved++
+	}
+
+	return moved, nil
+}
+
+func isJsonFile[T any](path string) bool {
+	rawJson, err := os.ReadFile(path)
+
+	if err != nil {
+		return false
+	}
+
+	var v T
+
+	return json.Unmarshal(rawJson, &v) == nil
+}
+
+func isHistoryLog(path string) bool {
+	_, err := os.Stat(path)
+
+	if err != nil {
+		return false
+	}
+
+	lines, err := readHistoryLines(path)
+
+	if err != nil {
+		return false
+	}
+
+	for _, line := range lines {
+		var entry HistoryEntry
+
+		err = json.Unmarshal(line, &entry)
+
+		if err != nil || entry.Word == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// moveFile renames src to dst, copying it when they are on different file
+// systems.
+func moveFile(src, dst string) error {
+	err := os.Rename(src, dst)
+
+	if err == nil {
+		return nil
+	}
+
+	data, readErr := os.ReadFile(src)
+
+	if readErr != nil {
+		return err
+	}
+
+	err = writeFileAtomic(dst, data, FilePerm)
+
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(src)
+}
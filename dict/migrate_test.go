@@ -0,0 +1,291 @@
+package dict
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// gzipped compresses content for a test file.
+func gzipped(t *testing.T, content string) string {
+	t.Helper()
+
+	data, err := compress([]byte(content))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+func TestMigrateCache(t *testing.T) {
+	wrappedDog, err := encodeCacheEntry([]byte(`[{"word":"dog","meanings":[]}]`), Validators{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldCat := `[{"word":"cat","meanings":[]}]`
+
+	tests := []struct {
+		name      string
+		old       map[string]string
+		oldLang   map[string]string
+		existing  map[string]string
+		wantMoved int
+		wantOld   []string
+		wantLang  []string
+		wantCat   string
+	}{
+		{
+			name:      "language directories",
+			oldLang:   map[string]string{"cat.json": catJson, "dog.json.gz": gzipped(t, string(wrappedDog))},
+			wantMoved: 2,
+			wantLang:  []string{"cat.json", "dog.json.gz"},
+			wantCat:   catJson,
+		},
+		{
+			name:      "root words into the default language",
+			old:       map[string]string{"Cat.json": catJson, "DOG.json.gz": gzipped(t, string(wrappedDog))},
+			wantMoved: 2,
+			wantLang:  []string{"cat.json", "dog.json.gz"},
+			wantCat:   catJson,
+		},
+		{
+			name:      "config files stay",
+			old:       map[string]string{"cat.json": catJson, "config.json": "{}", "aliases.json": "{}", "favorites.json": "[]", "counts.json": "{}", "history.log": ""},
+			wantMoved: 1,
+			wantOld:   []string{"aliases.json", "config.json", "counts.json", "favorites.json", "history.log"},
+			wantLang:  []string{"cat.json"},
+			wantCat:   catJson,
+		},
+		{
+			name:      "other JSON files stay",
+			old:       map[string]string{"cat.json": catJson, "package.json": `{"name":"proj"}`, "tsconfig.json": "{}", "list.json": "[1, 2]", "data.json": `[{"id":1}]`, "notes.json.gz": "not gzip"},
+			wantMoved: 1,
+			wantOld:   []string{"data.json", "list.json", "notes.json.gz", "package.json", "tsconfig.json"},
+			wantLang:  []string{"cat.json"},
+			wantCat:   catJson,
+		},
+		{
+			name:      "words already in the cache win",
+			old:       map[string]string{"Cat.json": oldCat},
+			oldLang:   map[string]string{"cat.json": oldCat, "dog.json": string(wrappedDog)},
+			existing:  map[string]string{"cat.json": catJson},
+			wantMoved: 1,
+			wantOld:   []string{"Cat.json"},
+			wantLang:  []string{"cat.json", "dog.json"},
+			wantCat:   catJson,
+		},
+		{
+			name: "nothing to move",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldDir := t.TempDir()
+			cacheDir := t.TempDir()
+			oldLangDir := filepath.Join(oldDir, DefaultLanguage)
+			langDir := filepath.Join(cacheDir, DefaultLanguage)
+
+			writeFiles(t, oldDir, tt.old)
+
+			for dir, files := range map[string]map[string]string{oldLangDir: tt.oldLang, langDir: tt.existing} {
+				if files == nil {
+					continue
+				}
+
+				err := os.Mkdir(dir, DirPerm)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				writeFiles(t, dir, files)
+			}
+
+			moved, err := MigrateCache(oldDir, cacheDir)
+
+			if err != nil {
+				t.Fatalf("MigrateCache() error = %v", err)
+			}
+
+			if moved != tt.wantMoved {
+				t.Errorf("MigrateCache() moved %d files, want %d", moved, tt.wantMoved)
+			}
+
+			if got := fileNames(t, oldDir); !slices.Equal(got, tt.wantOld) {
+				t.Errorf("files left in old directory = %v, want %v", got, tt.wantOld)
+			}
+
+			if got := fileNames(t, langDir); !slices.Equal(got, tt.wantLang) {
+				t.Errorf("files in language directory = %v, want %v", got, tt.wantLang)
+			}
+
+			if tt.wantCat == "" {
+				return
+			}
+
+			rawJson, err := FetchFromCache("cat", langDir)
+
+			if err != nil {
+				t.Fatalf("FetchFromCache() error = %v", err)
+			}
+
+			if string(rawJson) != tt.wantCat {
+				t.Errorf("FetchFromCache() = %s, want %s", rawJson, tt.wantCat)
+			}
+		})
+	}
+}
+
+func TestMigrateCacheRunsOnce(t *testing.T) {
+	oldDir := t.TempDir()
+	cacheDir := t.TempDir()
+	langDir := filepath.Join(cacheDir, DefaultLanguage)
+
+	writeFiles(t, oldDir, map[string]string{"cat.json": catJson})
+
+	moved, err := MigrateCache(oldDir, cacheDir)
+
+	if err != nil || moved != 1 {
+		t.Fatalf("first MigrateCache() = %d, %v, want 1, nil", moved, err)
+	}
+
+	writeFiles(t, oldDir, map[string]string{"dog.json": catJson})
+
+	moved, err = MigrateCache(oldDir, cacheDir)
+
+	if err != nil || moved != 0 {
+		t.Fatalf("second MigrateCache() = %d, %v, want 0, nil", moved, err)
+	}
+
+	if got := fileNames(t, oldDir); !slices.Equal(got, []string{"dog.json"}) {
+		t.Errorf("files left in old directory = %v, want [dog.json]", got)
+	}
+
+	if got := fileNames(t, langDir); !slices.Equal(got, []string{"cat.json"}) {
+		t.Errorf("files in language directory = %v, want [cat.json]", got)
+	}
+}
+
+func TestMigrateCacheSameDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"Cat.json": catJson})
+
+	moved, err := MigrateCache(dir, dir)
+
+	if err != nil || moved != 0 {
+		t.Fatalf("MigrateCache() = %d, %v, want 0, nil", moved, err)
+	}
+
+	if got := fileNames(t, dir); !slices.Equal(got, []string{"Cat.json"}) {
+		t.Errorf("files in directory = %v, want [Cat.json]", got)
+	}
+}
+
+func TestMigrateCacheMissingOldDir(t *testing.T) {
+	cacheDir := t.TempDir()
+
+	moved, err := MigrateCache(filepath.Join(cacheDir, "missing"), cacheDir)
+
+	if err != nil || moved != 0 {
+		t.Fatalf("MigrateCache() = %d, %v, want 0, nil", moved, err)
+	}
+}
+
+func TestMigrateData(t *testing.T) {
+	history := `{"word":"cat","time":"2026-01-02T03:04:05Z"}` + "\n"
+
+	tests := []struct {
+		name      string
+		old       map[string]string
+		existing  map[string]string
+		wantMoved int
+		wantOld   []string
+		wantData  []string
+	}{
+		{
+			name:      "every data file",
+			old:       map[string]string{"aliases.json": `{"kitty":"cat"}`, "favorites.json": `["cat"]`, "counts.json": `{"cat":2}`, "history.log": history},
+			wantMoved: 4,
+			wantData:  []string{"aliases.json", "counts.json", "favorites.json", "history.log"},
+		},
+		{
+			name:      "words and other files stay",
+			old:       map[string]string{"counts.json": `{"cat":2}`, "cat.json": catJson, "package.json": `{"name":"proj"}`, "config.json": "{}"},
+			wantMoved: 1,
+			wantOld:   []string{"cat.json", "config.json", "package.json"},
+			wantData:  []string{"counts.json"},
+		},
+		{
+			name:     "files that are not wordef data stay",
+			old:      map[string]string{"aliases.json": `{"name":1}`, "favorites.json": "{}", "counts.json": `["cat"]`, "history.log": "not json\n"},
+			wantOld:  []string{"aliases.json", "counts.json", "favorites.json", "history.log"},
+			wantData: nil,
+		},
+		{
+			name:      "data already in the config directory wins",
+			old:       map[string]string{"counts.json": `{"cat":2}`, "favorites.json": `["cat"]`},
+			existing:  map[string]string{"counts.json": `{"dog":1}`},
+			wantMoved: 1,
+			wantOld:   []string{"counts.json"},
+			wantData:  []string{"counts.json", "favorites.json"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldDir := t.TempDir()
+			dataDir := t.TempDir()
+
+			writeFiles(t, oldDir, tt.old)
+			writeFiles(t, dataDir, tt.existing)
+
+			moved, err := MigrateData(oldDir, dataDir)
+
+			if err != nil {
+				t.Fatalf("MigrateData() error = %v", err)
+			}
+
+			if moved != tt.wantMoved {
+				t.Errorf("MigrateData() moved %d files, want %d", moved, tt.wantMoved)
+			}
+
+			if got := fileNames(t, oldDir); !slices.Equal(got, tt.wantOld) {
+				t.Errorf("files left in old directory = %v, want %v", got, tt.wantOld)
+			}
+
+			if got := fileNames(t, dataDir); !slices.Equal(got, tt.wantData) {
+				t.Errorf("files in config directory = %v, want %v", got, tt.wantData)
+			}
+		})
+	}
+}
+
+func TestMigrateDataKeepsExistingCounts(t *testing.T) {
+	oldDir := t.TempDir()
+	dataDir := t.TempDir()
+
+	writeFiles(t, oldDir, map[string]string{"counts.json": `{"cat":2}`})
+	writeFiles(t, dataDir, map[string]string{"counts.json": `{"dog":1}`})
+
+	_, err := MigrateData(oldDir, dataDir)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts, err := readCounts(filepath.Join(dataDir, countsFileName))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if counts["dog"] != 1 || counts["cat"] != 0 {
+		t.Errorf("counts = %v, want only dog", counts)
+	}
+}
@@ -0,0 +1,59 @@
+package dict
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"time"
+)
+
+const (
+	notFoundExt = ".notfound"
+
+	DefaultNegativeTTL = time.Hour
+)
+
+// NegativeTTL is how long a word the API does not know is remembered, so
+// that looking it up again does not call the API. Zero disables it.
+var NegativeTTL time.Duration
+
+func notFoundPath(word, cacheDir string) string {
+	return path.Join(cacheDir, cacheFileReplacer.Replace(NormalizeWord(word))+notFoundExt)
+}
+
+// saveNotFound remembers that the API does not know a word, the file holds
+// the explanation given by the API.
+func saveNotFound(word, cacheDir string, notFound *WordNotFoundError) error {
+	return writeFileAtomic(notFoundPath(word, cacheDir), []byte(notFound.Details), FilePerm)
+}
+
+// fetchNotFound returns the saved not found error of a word, or nil when the
+// word was not looked up within NegativeTTL.
+func fetchNotFound(word, cacheDir string) *WordNotFoundError {
+	wordPath := notFoundPath(word, cacheDir)
+
+	info, err := os.Stat(wordPath)
+
+	if err != nil || time.Since(info.ModTime()) > NegativeTTL {
+		return nil
+	}
+
+	details, err := os.ReadFile(wordPath)
+
+	if err != nil {
+		return nil
+	}
+
+	return &WordNotFoundError{Word: word, Details: string(details)}
+}
+
+func removeNotFound(word, cacheDir string) error {
+	err := os.Remove(notFoundPath(word, cacheDir))
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,110 @@
+package dict
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSearchNegativeCache(t *testing.T) {
+	tests := []struct {
+		name         string
+		ttl          time.Duration
+		age          time.Duration
+		wantRequests int
+	}{
+		{"disabled", 0, 0, 2},
+		{"remembered", time.Hour, time.Minute, 1},
+		{"expired", time.Hour, 2 * time.Hour, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+
+			testServer(t, func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"title":"No Definitions Found","message":"Sorry pal.","resolution":"Try the web."}`))
+			})
+
+			setForTest(t, &NegativeTTL, tt.ttl)
+
+			cacheDir := t.TempDir()
+
+			_, err := Search(context.Background(), "qwxz", cacheDir, SearchOptions{})
+
+			if !errors.Is(err, ErrWordNotFound) {
+				t.Fatalf("Search() error = %v, want not found", err)
+			}
+
+			if tt.ttl > 0 {
+				modTime := time.Now().Add(-tt.age)
+
+				err = os.Chtimes(notFoundPath("qwxz", cacheDir), modTime, modTime)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, err = Search(context.Background(), "qwxz", cacheDir, SearchOptions{})
+
+			var notFound *WordNotFoundError
+
+			if !errors.As(err, &notFound) || notFound.Details != "Sorry pal. Try the web." {
+				t.Errorf("second Search() error = %v, want not found with the API's details", err)
+			}
+
+			if requests != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", requests, tt.wantRequests)
+			}
+
+			words, err := CachedWords(cacheDir)
+
+			if err != nil || len(words) > 0 {
+				t.Errorf("CachedWords() = %v, %v, want no words", words, err)
+			}
+		})
+	}
+}
+
+func TestSearchForgetsNotFoundOnceFound(t *testing.T) {
+	found := false
+
+	testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Write([]byte(catJson))
+	})
+
+	setForTest(t, &NegativeTTL, time.Hour)
+
+	cacheDir := t.TempDir()
+
+	_, err := Search(context.Background(), "cat", cacheDir, SearchOptions{})
+
+	if !errors.Is(err, ErrWordNotFound) {
+		t.Fatalf("Search() error = %v, want not found", err)
+	}
+
+	found = true
+
+	_, err = Search(context.Background(), "cat", cacheDir, SearchOptions{Refresh: true})
+
+	if err != nil {
+		t.Fatalf("Search() with Refresh error = %v", err)
+	}
+
+	_, err = os.Stat(notFoundPath("cat", cacheDir))
+
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("not found file left after the word was found: %v", err)
+	}
+}
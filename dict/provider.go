@@ -0,0 +1,101 @@
+package dict
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Provider looks words up in a dictionary service and returns them in the
+// WordInfo shape of dictionaryapi.dev, whatever shape the service uses.
+type Provider interface {
+	Name() string
+	Lookup(ctx context.Context, word, lang string) ([]WordInfo, error)
+}
+
+const DefaultProvider = "dictionaryapi"
+
+// Providers are the names accepted by NewProvider.
+var Providers = []string{DefaultProvider, "wordsapi"}
+
+// NewProvider returns the provider called name, apiKey is only used by
+// providers that need one.
+func NewProvider(name, apiKey string) (Provider, error) {
+	switch name {
+	case DefaultProvider:
+		return DictionaryAPI{}, nil
+	case "wordsapi":
+		return &WordsAPI{Key: apiKey}, nil
+	}
+
+	return nil, fmt.Errorf("Unknown provider %q, expected one of: %s", name, strings.Join(Providers, ", "))
+}
+
+// ConditionalProvider is a Provider that can ask its service whether the
+// cached response of a word has changed. Search caches the responses of
+// such providers as they are sent.
+type ConditionalProvider interface {
+	Provider
+	// FetchConditional returns the response for a word and its validators,
+	// or ErrNotModified when the response cached with cached is current.
+	FetchConditional(ctx context.Context, word, lang string, cached Validators) (rawJson []byte, fresh Validators, err error)
+}
+
+// DictionaryAPI is the free dictionaryapi.dev service at BaseURL.
+type DictionaryAPI struct{}
+
+func (DictionaryAPI) Name() string {
+	return DefaultProvider
+}
+
+func (DictionaryAPI) Lookup(ctx context.Context, word, lang string) (parsed []WordInfo, err error) {
+	rawJson, err := FetchFromAPI(ctx, word, lang)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(rawJson, &parsed)
+
+	if err != nil {
+		apiErr := parseAPIError(rawJson)
+
+		if apiErr != nil {
+			return nil, fmt.Errorf("dictionary API error: %w", apiErr)
+		}
+
+		return nil, err
+	}
+
+	return parsed, nil
+}
+
+func (DictionaryAPI) FetchConditional(ctx context.Context, word, lang string, cached Validators) (rawJson []byte, fresh Validators, err error) {
+	return fetchConditional(ctx, word, lang, cached)
+}
+
+// fetchProvider looks a word up with p, or with DictionaryAPI when p is nil,
+// and returns the response to cache. Providers that are not conditional
+// have their result encoded like a dictionaryapi.dev response.
+func fetchProvider(ctx context.Context, p Provider, word, lang string, cached Validators) (rawJson []byte, fresh Validators, err error) {
+	if p == nil {
+		p = DictionaryAPI{}
+	}
+
+	conditional, ok := p.(ConditionalProvider)
+
+	if ok {
+		return conditional.FetchConditional(ctx, word, lang, cached)
+	}
+
+	parsed, err := p.Lookup(ctx, word, lang)
+
+	if err != nil {
+		return nil, fresh, err
+	}
+
+	rawJson, err = json.Marshal(parsed)
+
+	return rawJson, fresh, err
+}
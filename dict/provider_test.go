@@ -0,0 +1,267 @@
+package dict
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubProvider answers every lookup with the same entries or error.
+type stubProvider struct {
+	entries []WordInfo
+	err     error
+	lookups int
+}
+
+func (p *stubProvider) Name() string {
+	return "stub"
+}
+
+func (p *stubProvider) Lookup(ctx context.Context, word, lang string) ([]WordInfo, error) {
+	p.lookups++
+
+	return p.entries, p.err
+}
+
+// conditionalStub is a stubProvider that reports responses cached with its
+// ETag as not modified.
+type conditionalStub struct {
+	stubProvider
+	sent []Validators
+}
+
+func (p *conditionalStub) FetchConditional(ctx context.Context, word, lang string, cached Validators) ([]byte, Validators, error) {
+	p.sent = append(p.sent, cached)
+
+	if cached.ETag == `"stub"` {
+		return nil, cached, ErrNotModified
+	}
+
+	return []byte(catJson), Validators{ETag: `"stub"`}, nil
+}
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"dictionaryapi", "dictionaryapi", false},
+		{"wordsapi", "wordsapi", false},
+		{"merriam-webster", "", true},
+	}
+
+	for _, tt := range tests {
+		p, err := NewProvider(tt.name, "key")
+
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewProvider(%q) error = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+
+		if err == nil && p.Name() != tt.want {
+			t.Errorf("NewProvider(%q).Name() = %q, want %q", tt.name, p.Name(), tt.want)
+		}
+	}
+}
+
+func TestSearchWithProvider(t *testing.T) {
+	testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("provider lookup reached dictionaryapi.dev: %s", r.URL.Path)
+	})
+
+	tests := []struct {
+		name        string
+		provider    *stubProvider
+		wantWord    string
+		wantErr     string
+		wantLookups int
+	}{
+		{
+			name:        "found and cached",
+			provider:    &stubProvider{entries: []WordInfo{{Word: "stub cat", Meanings: []Meaning{{PartOfSpeech: "noun"}}}}},
+			wantWord:    "stub cat",
+			wantLookups: 1,
+		},
+		{
+			name:        "not found",
+			provider:    &stubProvider{err: &WordNotFoundError{Word: "cat"}},
+			wantErr:     "No definitions found for 'cat'",
+			wantLookups: 2,
+		},
+		{
+			name:        "no entries",
+			provider:    &stubProvider{entries: []WordInfo{}},
+			wantErr:     "No results for 'cat'",
+			wantLookups: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheDir := t.TempDir()
+
+			for range 2 {
+				entries, err := Search(context.Background(), "cat", cacheDir, SearchOptions{Provider: tt.provider})
+
+				if tt.wantErr != "" {
+					if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+						t.Fatalf("Search() error = %v, want %q", err, tt.wantErr)
+					}
+
+					continue
+				}
+
+				if err != nil {
+					t.Fatalf("Search() error = %v", err)
+				}
+
+				if len(entries) != 1 || entries[0].Word != tt.wantWord {
+					t.Errorf("Search() = %v, want %s", entries, tt.wantWord)
+				}
+			}
+
+			if tt.provider.lookups != tt.wantLookups {
+				t.Errorf("provider lookups = %d, want %d", tt.provider.lookups, tt.wantLookups)
+			}
+		})
+	}
+}
+
+func TestSearchWithConditionalProvider(t *testing.T) {
+	testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("provider lookup reached dictionaryapi.dev: %s", r.URL.Path)
+	})
+
+	setForTest(t, &CacheTTL, time.Hour)
+
+	provider := &conditionalStub{}
+	cacheDir := t.TempDir()
+
+	_, err := Search(context.Background(), "cat", cacheDir, SearchOptions{Provider: provider})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old := time.Now().Add(-2 * time.Hour)
+
+	err = os.Chtimes(cachePath("cat", cacheDir), old, old)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Search(context.Background(), "cat", cacheDir, SearchOptions{Provider: provider})
+
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Search() after not modified = %v, %v, want the cached entry", entries, err)
+	}
+
+	if len(provider.sent) != 2 || provider.sent[0].ETag != "" || provider.sent[1].ETag != `"stub"` {
+		t.Errorf("validators sent = %v, want none and then the cached ETag", provider.sent)
+	}
+
+	if provider.lookups != 0 {
+		t.Errorf("Lookup called %d times, want conditional fetches only", provider.lookups)
+	}
+}
+
+func TestDictionaryAPILookup(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{"found", http.StatusOK, catJson, ""},
+		{"not found", http.StatusNotFound, `{"title":"No Definitions Found","message":"Sorry pal.","resolution":"Try the web."}`, "No definitions found for 'cat'. Sorry pal. Try the web."},
+		{"error body", http.StatusOK, `{"title":"Oops","message":"Something broke.","resolution":"Try later."}`, "dictionary API error: Oops: Something broke. Try later."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			entries, err := DictionaryAPI{}.Lookup(context.Background(), "cat", "en")
+
+			if tt.wantErr == "" {
+				if err != nil || len(entries) != 1 {
+					t.Errorf("Lookup() = %v, %v, want cat", entries, err)
+				}
+
+				return
+			}
+
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Lookup() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWordsAPILookup(t *testing.T) {
+	var gotKey string
+
+	server := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("X-RapidAPI-Key")
+
+		if !strings.HasSuffix(r.URL.Path, "/cat") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Write([]byte(`{"word":"cat","pronunciation":{"all":"kæt"},"results":[
+			{"definition":"feline mammal","partOfSpeech":"noun","synonyms":["true cat"],"examples":["the cat purred"]},
+			{"definition":"to vomit","partOfSpeech":"verb"},
+			{"definition":"a spiteful woman","partOfSpeech":"noun"}]}`))
+	})
+
+	setForTest(t, &WordsAPIURL, server.URL+"/words/")
+
+	tests := []struct {
+		name    string
+		key     string
+		word    string
+		lang    string
+		wantErr error
+	}{
+		{"found", "secret", "cat", "en", nil},
+		{"not found", "secret", "qwxz", "en", ErrWordNotFound},
+		{"missing key", "", "cat", "en", ErrMissingAPIKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := (&WordsAPI{Key: tt.key}).Lookup(context.Background(), tt.word, tt.lang)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Lookup() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if gotKey != tt.key {
+				t.Errorf("API key sent = %q, want %q", gotKey, tt.key)
+			}
+
+			info := entries[0]
+
+			if info.Phonetic != "/kæt/" || len(info.Meanings) != 2 || len(info.Meanings[0].Definitions) != 2 {
+				t.Errorf("Lookup() = %+v, want nouns grouped before verbs", info)
+			}
+
+			if info.Meanings[0].Definitions[0].Example != "the cat purred" {
+				t.Errorf("example = %q, want the first WordsAPI example", info.Meanings[0].Definitions[0].Example)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package dict
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const DefaultRate = 5
+
+// Rate is the most API requests sent per second, zero or less disables the
+// limit. Cache hits never count against it.
+var Rate = DefaultRate
+
+var (
+	rateMu      sync.Mutex
+	nextRequest time.Time
+)
+
+// waitForRate blocks until the next API request may be sent, spacing
+// requests from all goroutines 1/Rate seconds apart.
+func waitForRate(ctx context.Context) error {
+	if Rate <= 0 {
+		return nil
+	}
+
+	rateMu.Lock()
+
+	slot := time.Now()
+
+	if nextRequest.After(slot) {
+		slot = nextRequest
+	}
+
+	nextRequest = slot.Add(time.Second / time.Duration(Rate))
+
+	rateMu.Unlock()
+
+	delay := time.Until(slot)
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,107 @@
+package dict
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// resetRate forgets the requests sent by earlier tests.
+func resetRate(t *testing.T) {
+	t.Helper()
+
+	rateMu.Lock()
+	nextRequest = time.Time{}
+	rateMu.Unlock()
+}
+
+func TestWaitForRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		rate     int
+		requests int
+		min, max time.Duration
+	}{
+		{"disabled", 0, 5, 0, 50 * time.Millisecond},
+		{"spaced", 20, 5, 200 * time.Millisecond, 400 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setForTest(t, &Rate, tt.rate)
+			resetRate(t)
+
+			var wg sync.WaitGroup
+
+			start := time.Now()
+
+			for range tt.requests {
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+
+					err := waitForRate(context.Background())
+
+					if err != nil {
+						t.Error(err)
+					}
+				}()
+			}
+
+			wg.Wait()
+
+			elapsed := time.Since(start)
+
+			if elapsed < tt.min || elapsed > tt.max {
+				t.Errorf("%d requests at %d per second took %s, want between %s and %s", tt.requests, tt.rate, elapsed, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestWaitForRateCancelled(t *testing.T) {
+	setForTest(t, &Rate, 1)
+	resetRate(t)
+
+	err := waitForRate(context.Background())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err = waitForRate(ctx)
+
+	if err == nil {
+		t.Error("waitForRate() error = nil, want the context error")
+	}
+}
+
+func TestCacheHitsAreNotRateLimited(t *testing.T) {
+	testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(catJson))
+	})
+
+	setForTest(t, &Rate, 1)
+	resetRate(t)
+
+	cacheDir := t.TempDir()
+	start := time.Now()
+
+	for range 5 {
+		_, err := Search(context.Background(), "cat", cacheDir, SearchOptions{})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("one fetch and four cache hits took %s at 1 request per second", elapsed)
+	}
+}
@@ -0,0 +1,181 @@
+package dict
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+const DefaultWordsAPIURL = "https://wordsapiv1.p.rapidapi.com/words/"
+
+// WordsAPIURL is the WordsAPI endpoint, the word is appended to it.
+var WordsAPIURL = DefaultWordsAPIURL
+
+var ErrMissingAPIKey = errors.New("WordsAPI needs an API key, set WORDEF_API_KEY")
+
+// WordsAPI is the English only wordsapi.com service, reached through
+// RapidAPI with an API key.
+type WordsAPI struct {
+	Key string
+}
+
+// wordsAPIResponse is the part of a WordsAPI response wordef shows.
+type wordsAPIResponse struct {
+	Word    string `json:"word"`
+	Results []struct {
+		Definition   string   `json:"definition"`
+		PartOfSpeech string   `json:"partOfSpeech"`
+		Synonyms     []string `json:"synonyms"`
+		Antonyms     []string `json:"antonyms"`
+		Examples     []string `json:"examples"`
+	} `json:"results"`
+	// Pronunciation is either a string or an object with an "all" field.
+	Pronunciation json.RawMessage `json:"pronunciation"`
+}
+
+func (*WordsAPI) Name() string {
+	return "wordsapi"
+}
+
+func (w *WordsAPI) Lookup(ctx context.Context, word, lang string) ([]WordInfo, error) {
+	if w.Key == "" {
+		return nil, ErrMissingAPIKey
+	}
+
+	if lang != DefaultLanguage {
+		return nil, fmt.Errorf("WordsAPI only supports English, not %q", lang)
+	}
+
+	err := ValidateWord(word)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = waitForRate(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	requestUrl := WordsAPIURL + url.PathEscape(word)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestUrl, nil)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("X-RapidAPI-Key", w.Key)
+
+	Logger.Debug("Requesting word from WordsAPI", "url", requestUrl)
+
+	resp, err := HTTPClient.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, &WordNotFoundError{Word: word}
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("WordsAPI rejected the API key: %w", &APIStatusError{StatusCode: resp.StatusCode})
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return nil, &APIStatusError{StatusCode: resp.StatusCode}
+	}
+
+	rawJson, err := io.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read response body: %w", err)
+	}
+
+	metricsFrom(ctx).recordFetch(len(rawJson))
+
+	var parsed wordsAPIResponse
+
+	err = json.Unmarshal(rawJson, &parsed)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode WordsAPI response: %w", err)
+	}
+
+	if len(parsed.Results) == 0 {
+		return nil, &WordNotFoundError{Word: word}
+	}
+
+	return []WordInfo{parsed.wordInfo()}, nil
+}
+
+// wordInfo groups the results of a WordsAPI response by part of speech, in
+// the order the parts of speech first appear.
+func (r *wordsAPIResponse) wordInfo() WordInfo {
+	info := WordInfo{Word: r.Word}
+
+	phonetic := r.pronunciation()
+
+	if phonetic != "" {
+		info.Phonetic = "/" + phonetic + "/"
+		info.Phonetics = []Phonetic{{Text: info.Phonetic}}
+	}
+
+	index := make(map[string]int)
+
+	for _, result := range r.Results {
+		i, ok := index[result.PartOfSpeech]
+
+		if !ok {
+			i = len(info.Meanings)
+			index[result.PartOfSpeech] = i
+			info.Meanings = append(info.Meanings, Meaning{PartOfSpeech: result.PartOfSpeech})
+		}
+
+		definition := Definition{
+			Definition: result.Definition,
+			Synonyms:   stringsToAny(result.Synonyms),
+			Antonyms:   stringsToAny(result.Antonyms),
+		}
+
+		if len(result.Examples) > 0 {
+			definition.Example = result.Examples[0]
+		}
+
+		info.Meanings[i].Definitions = append(info.Meanings[i].Definitions, definition)
+	}
+
+	return info
+}
+
+func (r *wordsAPIResponse) pronunciation() string {
+	var text string
+
+	if json.Unmarshal(r.Pronunciation, &text) == nil {
+		return text
+	}
+
+	var all struct {
+		All string `json:"all"`
+	}
+
+	json.Unmarshal(r.Pronunciation, &all)
+
+	return all.All
+}
+
+func stringsToAny(values []string) []any {
+	result := make([]any, len(values))
+
+	for i, v := range values {
+		result[i] = v
+	}
+
+	return result
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"wordef/dict"
+)
+
+// renderFull writes every field of a word, grouped by part of speech.
+// Sections without content are left out.
+func renderFull(w io.Writer, wordInfo dict.WordInfo, color bool) {
+	fmt.Fprintln(w, bold(displayWord(wordInfo.Word), color))
+
+	primary, others := phoneticSpellings(wordInfo)
+
+	if primary != "" {
+		fmt.Fprintln(w, "Phonetic Spellings:", strings.Join(labelPhonetics(append([]string{primary}, others...)), ", "))
+	}
+
+	if wordInfo.Origin != "" {
+		fmt.Fprintln(w, "Origin:", wordInfo.Origin)
+	}
+
+	fmt.Fprintf(w, "Syllables: %d (estimated), Letters: %d\n", syllableCount(wordInfo.Word), letterCount(wordInfo.Word))
+
+	var audio []string
+
+	for _, p := range wordInfo.Phonetics {
+		if p.Audio != "" {
+			audio = append(audio, p.Audio)
+		}
+	}
+
+	if len(audio) > 0 {
+		fmt.Fprintln(w, "Audio:")
+
+		for _, a := range audio {
+			fmt.Fprintln(w, "  "+a)
+		}
+	}
+
+	for _, m := range wordInfo.Meanings {
+		synonyms := uniqueSorted(anyToStrings(m.Synonyms))
+		antonyms := uniqueSorted(anyToStrings(m.Antonyms))
+
+		if len(m.Definitions) == 0 && len(synonyms) == 0 && len(antonyms) == 0 {
+			continue
+		}
+
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, bold(strings.ToUpper(m.PartOfSpeech), color))
+
+		for i, d := range m.Definitions {
+			fmt.Fprintf(w, "  %d. %s\n", i+1, d.Definition)
+
+			if d.Example != "" {
+				fmt.Fprintf(w, "     \"%s\"\n", d.Example)
+			}
+
+			renderRelatedLine(w, "     ", "Synonyms", uniqueSorted(anyToStrings(d.Synonyms)))
+			renderRelatedLine(w, "     ", "Antonyms", uniqueSorted(anyToStrings(d.Antonyms)))
+		}
+
+		renderRelatedLine(w, "  ", "Synonyms", synonyms)
+		renderRelatedLine(w, "  ", "Antonyms", antonyms)
+	}
+}
+
+func renderRelatedLine(w io.Writer, indent, label string, words []string) {
+	if len(words) > 0 {
+		fmt.Fprintf(w, "%s%s: %s\n", indent, label, strings.Join(words, ", "))
+	}
+}
@@ -2,7 +2,11 @@ module wordef
 
 go 1.24.2
 
-require github.com/olekukonko/tablewriter v0.0.6-0.20250407213420-926ba07447b4
+require (
+	github.com/olekukonko/tablewriter v0.0.6-0.20250407213420-926ba07447b4
+	golang.org/x/sys v0.33.0
+	golang.org/x/term v0.32.0
+)
 
 require (
 	github.com/mattn/go-runewidth v0.0.16 // indirect
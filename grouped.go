@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"wordef/dict"
+)
+
+// renderGrouped writes the definitions of a word like a printed dictionary,
+// a heading per part of speech followed by its numbered definitions. It
+// honours the same options as the definitions table.
+func renderGrouped(w io.Writer, wordInfo dict.WordInfo, opts searchOptions) {
+	rows := 0
+	omitted := 0
+
+	var clamped []string
+
+	for _, m := range wordInfo.Meanings {
+		definitions := m.Definitions
+		first := 0
+
+		if opts.sense > 0 && len(definitions) > 0 {
+			index, ok := senseIndex(len(definitions), opts.sense)
+
+			if !ok {
+				clamped = append(clamped, fmt.Sprintf("%s has only %d", m.PartOfSpeech, len(definitions)))
+			}
+
+			definitions = definitions[index : index+1]
+			first = index
+		}
+
+		if opts.limit > 0 && len(definitions) > opts.limit {
+			definitions = definitions[:opts.limit]
+		}
+
+		heading := false
+
+		for i, d := range definitions {
+			if opts.definitionsCount > 0 && rows >= opts.definitionsCount {
+				omitted++
+				continue
+			}
+
+			rows++
+
+			if !heading {
+				if rows > 1 {
+					fmt.Fprintln(w)
+				}
+
+				fmt.Fprintln(w, bold(m.PartOfSpeech, opts.color))
+				heading = true
+			}
+
+			fmt.Fprintf(w, "  %d. %s\n", first+i+1, d.Definition)
+
+			if !opts.noExamples && d.Example != "" {
+				fmt.Fprintf(w, "     \"%s\"\n", d.Example)
+			}
+		}
+	}
+
+	if len(clamped) > 0 {
+		fmt.Fprintf(w, "(%s, showing the last definition instead of sense %d)\n", strings.Join(clamped, ", "), opts.sense)
+	}
+
+	if omitted > 0 {
+		fmt.Fprintf(w, "(+%d more, use -n 0 to see all)\n", omitted)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"wordef/dict"
+)
+
+// jsonLine is a line of --jsonl output, holding either the entries of a
+// word or the reason it could not be looked up.
+type jsonLine struct {
+	Word    string `json:"word"`
+	Entries any    `json:"entries,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// handleJsonLinesCommand prints a JSON object per word, in the order of
+// words, writing each line as soon as its lookup is done.
+func handleJsonLinesCommand(words []string, cacheDir string, opts searchOptions) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+
+	var errs []error
+
+	for r := range dict.SearchStream(context.Background(), words, cacheDir, opts.SearchOptions, opts.workers) {
+		r = checkResult(r, cacheDir, opts)
+		line := jsonLine{Word: r.Word}
+
+		if errors.Is(r.Err, dict.ErrWordNotFound) {
+			line.Error = "not found"
+		} else if r.Err != nil {
+			line.Error = r.Err.Error()
+		} else {
+			line.Entries = jsonEntries(r.Entries, opts)
+		}
+
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+
+		err := encoder.Encode(line)
+
+		if err != nil {
+			return fmt.Errorf("Failed to encode JSON output: %w", err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return &batchError{errs: errs, total: len(words)}
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+)
+
+// testAPI points the dictionary API at handler for the length of a test.
+func testAPI(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	configureForTest(t, map[string]string{
+		"WORDEF_API_URL": server.URL,
+		"WORDEF_RATE":    "0",
+		"WORDEF_RETRIES": "1",
+	})
+}
+
+func TestHandleJsonLinesCommand(t *testing.T) {
+	testAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		word := path.Base(r.URL.Path)
+
+		if word == "qwxz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		fmt.Fprintf(w, `[{"word":%q,"meanings":[{"partOfSpeech":"noun","definitions":[{"definition":"A %s."}]}]}]`, word, word)
+	})
+
+	tests := []struct {
+		name      string
+		words     []string
+		opts      searchOptions
+		wantWords []string
+		wantErrs  []string
+		wantErr   bool
+	}{
+		{
+			name:      "found",
+			words:     []string{"cat", "dog", "eel"},
+			wantWords: []string{"cat", "dog", "eel"},
+			wantErrs:  []string{"", "", ""},
+		},
+		{
+			name:      "not found",
+			words:     []string{"cat", "qwxz", "dog"},
+			wantWords: []string{"cat", "qwxz", "dog"},
+			wantErrs:  []string{"", "not found", ""},
+			wantErr:   true,
+		},
+		{
+			name:      "normalized",
+			words:     []string{"cat"},
+			opts:      searchOptions{jsonNormalized: true},
+			wantWords: []string{"cat"},
+			wantErrs:  []string{""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.workers = 2
+
+			var err error
+
+			out := captureStdout(t, func() {
+				err = handleJsonLinesCommand(tt.words, t.TempDir(), opts)
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("handleJsonLinesCommand() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			scanner := bufio.NewScanner(strings.NewReader(out))
+			i := 0
+
+			for ; scanner.Scan(); i++ {
+				var line struct {
+					Word    string            `json:"word"`
+					Entries []json.RawMessage `json:"entries"`
+					Error   string            `json:"error"`
+				}
+
+				err := json.Unmarshal(scanner.Bytes(), &line)
+
+				if err != nil {
+					t.Fatalf("line %d is not a JSON object: %v\n%s", i+1, err, scanner.Text())
+				}
+
+				if i >= len(tt.wantWords) {
+					t.Fatalf("unexpected line %d: %s", i+1, scanner.Text())
+				}
+
+				if line.Word != tt.wantWords[i] || line.Error != tt.wantErrs[i] {
+					t.Errorf("line %d = %s, want word %q and error %q", i+1, scanner.Text(), tt.wantWords[i], tt.wantErrs[i])
+				}
+
+				if line.Error == "" && len(line.Entries) != 1 {
+					t.Errorf("line %d has %d entries, want 1", i+1, len(line.Entries))
+				}
+
+				if tt.opts.jsonNormalized && !strings.Contains(scanner.Text(), `"pos":"noun"`) {
+					t.Errorf("line %d is not normalized: %s", i+1, scanner.Text())
+				}
+			}
+
+			if i != len(tt.wantWords) {
+				t.Errorf("got %d lines, want %d", i, len(tt.wantWords))
+			}
+		})
+	}
+}
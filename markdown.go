@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"wordef/dict"
+)
+
+// renderMarkdown writes a word as a Markdown section, ready to be appended
+// to a notes file.
+func renderMarkdown(w io.Writer, wordInfo dict.WordInfo) {
+	fmt.Fprintf(w, "## %s\n\n", displayWord(wordInfo.Word))
+
+	primary, _ := phoneticSpellings(wordInfo)
+
+	if primary != "" {
+		fmt.Fprintf(w, "*%s*\n\n", primary)
+	}
+
+	for _, m := range wordInfo.Meanings {
+		if len(m.Definitions) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "### %s\n\n", m.PartOfSpeech)
+
+		for _, d := range m.Definitions {
+			fmt.Fprintf(w, "- %s\n", d.Definition)
+
+			if d.Example != "" {
+				fmt.Fprintf(w, "  > %s\n", d.Example)
+			}
+		}
+
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import "wordef/dict"
+
+// normalizedWord is the --json-normalized schema, a typed and stable subset
+// of the API response.
+type normalizedWord struct {
+	Word      string              `json:"word"`
+	Phonetics []string            `json:"phonetics"`
+	Meanings  []normalizedMeaning `json:"meanings"`
+}
+
+type normalizedMeaning struct {
+	PartOfSpeech string                 `json:"pos"`
+	Definitions  []normalizedDefinition `json:"definitions"`
+}
+
+type normalizedDefinition struct {
+	Text     string   `json:"text"`
+	Example  string   `json:"example"`
+	Synonyms []string `json:"synonyms"`
+	Antonyms []string `json:"antonyms"`
+}
+
+func normalizeWordInfo(wordInfo dict.WordInfo) normalizedWord {
+	phonetics := []string{}
+	primary, others := phoneticSpellings(wordInfo)
+
+	if primary != "" {
+		phonetics = append(append(phonetics, primary), others...)
+	}
+
+	normalized := normalizedWord{
+		Word:      wordInfo.Word,
+		Phonetics: phonetics,
+		Meanings:  []normalizedMeaning{},
+	}
+
+	for _, m := range wordInfo.Meanings {
+		meaning := normalizedMeaning{
+			PartOfSpeech: m.PartOfSpeech,
+			Definitions:  []normalizedDefinition{},
+		}
+
+		for _, d := range m.Definitions {
+			meaning.Definitions = append(meaning.Definitions, normalizedDefinition{
+				Text:     d.Definition,
+				Example:  d.Example,
+				Synonyms: uniqueSorted(anyToStrings(d.Synonyms)),
+				Antonyms: uniqueSorted(anyToStrings(d.Antonyms)),
+			})
+		}
+
+		normalized.Meanings = append(normalized.Meanings, meaning)
+	}
+
+	return normalized
+}
+
+// jsonEntries returns the value printed for a word's entries, either the
+// API response or, with --json-normalized, the normalized schema.
+func jsonEntries(entries []dict.WordInfo, opts searchOptions) any {
+	if !opts.jsonNormalized {
+		return entries
+	}
+
+	normalized := make([]normalizedWord, 0, len(entries))
+
+	for _, wordInfo := range entries {
+		normalized = append(normalized, normalizeWordInfo(wordInfo))
+	}
+
+	return normalized
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"wordef/dict"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestJsonNormalizedGolden(t *testing.T) {
+	tests := []struct {
+		fixture string
+		golden  string
+	}{
+		{"cat.json", "cat.normalized.golden"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			rawJson, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var entries []dict.WordInfo
+
+			err = json.Unmarshal(rawJson, &entries)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var printErr error
+
+			got := captureStdout(t, func() {
+				printErr = printJson(jsonEntries(entries, searchOptions{jsonNormalized: true}))
+			})
+
+			if printErr != nil {
+				t.Fatal(printErr)
+			}
+
+			goldenPath := filepath.Join("testdata", tt.golden)
+
+			if *update {
+				err = os.WriteFile(goldenPath, []byte(got), 0o644)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(goldenPath)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != string(want) {
+				t.Errorf("--json-normalized output differs from %s, run go test -update after checking it:\n%s", goldenPath, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"wordef/dict"
+)
+
+// defaultOpenUrl is opened for words whose entry has no source page.
+const defaultOpenUrl = "https://en.wiktionary.org/wiki/{word}"
+
+func openInBrowser(pageUrl string) error {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", pageUrl)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", pageUrl)
+	default:
+		cmd = exec.Command("xdg-open", pageUrl)
+	}
+
+	err := cmd.Start()
+
+	if err != nil {
+		return fmt.Errorf("Failed to open a browser, visit %s instead: %w", pageUrl, err)
+	}
+
+	return cmd.Process.Release()
+}
+
+// wordPageUrl returns the web page of a word: WORDEF_OPEN_URL with {word}
+// replaced when it is set, otherwise the source page of the entry.
+func wordPageUrl(word, cacheDir string, opts searchOptions) (string, error) {
+	template := os.Getenv("WORDEF_OPEN_URL")
+
+	if template != "" {
+		return strings.ReplaceAll(template, "{word}", url.PathEscape(dict.NormalizeWord(word))), nil
+	}
+
+	resp, err := dict.Search(context.Background(), word, cacheDir, opts.SearchOptions)
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to search for word %s: %w", word, err)
+	}
+
+	for _, wordInfo := range resp {
+		if len(wordInfo.SourceUrls) > 0 {
+			return wordInfo.SourceUrls[0], nil
+		}
+	}
+
+	return strings.ReplaceAll(defaultOpenUrl, "{word}", url.PathEscape(dict.NormalizeWord(word))), nil
+}
+
+func handleOpenCommand(word, cacheDir string, opts searchOptions) error {
+	pageUrl, err := wordPageUrl(word, cacheDir, opts)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Opening", pageUrl)
+
+	return openInBrowser(pageUrl)
+}
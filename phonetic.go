@@ -0,0 +1,77 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"wordef/dict"
+)
+
+// ipaRunes are IPA letters outside the IPA Extensions and Spacing Modifier
+// Letters blocks.
+var ipaRunes = []rune("æðθŋçøœβχ")
+
+// isIPA reports whether a phonetic spelling looks like IPA rather than a
+// plain respelling such as "KAT": it is wrapped in slashes or brackets, or
+// uses IPA symbols.
+func isIPA(phonetic string) bool {
+	phonetic = strings.TrimSpace(phonetic)
+
+	if len(phonetic) > 2 && (strings.HasPrefix(phonetic, "/") && strings.HasSuffix(phonetic, "/") ||
+		strings.HasPrefix(phonetic, "[") && strings.HasSuffix(phonetic, "]")) {
+		return true
+	}
+
+	return strings.ContainsFunc(phonetic, func(r rune) bool {
+		return unicode.In(r, ipaExtensions, spacingModifiers) || slices.Contains(ipaRunes, r)
+	})
+}
+
+var (
+	ipaExtensions    = &unicode.RangeTable{R16: []unicode.Range16{{Lo: 0x0250, Hi: 0x02af, Stride: 1}}}
+	spacingModifiers = &unicode.RangeTable{R16: []unicode.Range16{{Lo: 0x02b0, Hi: 0x02ff, Stride: 1}}}
+)
+
+// labelPhonetic marks a phonetic spelling as IPA or a respelling.
+func labelPhonetic(phonetic string) string {
+	if isIPA(phonetic) {
+		return phonetic + " (IPA)"
+	}
+
+	return phonetic + " (respelling)"
+}
+
+func labelPhonetics(phonetics []string) []string {
+	labeled := make([]string, len(phonetics))
+
+	for i, p := range phonetics {
+		labeled[i] = labelPhonetic(p)
+	}
+
+	return labeled
+}
+
+// onlyIPA drops the phonetic spellings of a word that are not IPA, audio
+// links are kept.
+func onlyIPA(wordInfo dict.WordInfo) dict.WordInfo {
+	if !isIPA(wordInfo.Phonetic) {
+		wordInfo.Phonetic = ""
+	}
+
+	var phonetics []dict.Phonetic
+
+	for _, p := range wordInfo.Phonetics {
+		if !isIPA(p.Text) {
+			p.Text = ""
+		}
+
+		if p.Text != "" || p.Audio != "" {
+			phonetics = append(phonetics, p)
+		}
+	}
+
+	wordInfo.Phonetics = phonetics
+
+	return wordInfo
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/olekukonko/tablewriter"
+
+	"wordef/dict"
+)
+
+func TestIsIPA(t *testing.T) {
+	tests := []struct {
+		phonetic string
+		want     bool
+	}{
+		{"/ˈæpəl/", true},
+		{"/kæt/", true},
+		{"[ˈwɔːtə]", true},
+		{"/kat/", true},
+		{" /kat/ ", true},
+		{"ˈæpəl", true},
+		{"θɪŋk", true},
+		{"ðɪs", true},
+		{"kʰæt", true},
+		{"KAT", false},
+		{"AP-uhl", false},
+		{"kat", false},
+		{"//", false},
+		{"/kat", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := isIPA(tt.phonetic); got != tt.want {
+			t.Errorf("isIPA(%q) = %v, want %v", tt.phonetic, got, tt.want)
+		}
+	}
+}
+
+func TestLabelPhonetic(t *testing.T) {
+	tests := []struct {
+		phonetic string
+		want     string
+	}{
+		{"/ˈæpəl/", "/ˈæpəl/ (IPA)"},
+		{"AP-uhl", "AP-uhl (respelling)"},
+	}
+
+	for _, tt := range tests {
+		if got := labelPhonetic(tt.phonetic); got != tt.want {
+			t.Errorf("labelPhonetic(%q) = %q, want %q", tt.phonetic, got, tt.want)
+		}
+	}
+}
+
+func TestOnlyIPA(t *testing.T) {
+	tests := []struct {
+		name string
+		in   dict.WordInfo
+		want dict.WordInfo
+	}{
+		{
+			name: "keeps IPA",
+			in: dict.WordInfo{Phonetic: "/kæt/", Phonetics: []dict.Phonetic{
+				{Text: "/kæt/", Audio: "cat.mp3"},
+			}},
+			want: dict.WordInfo{Phonetic: "/kæt/", Phonetics: []dict.Phonetic{
+				{Text: "/kæt/", Audio: "cat.mp3"},
+			}},
+		},
+		{
+			name: "drops respellings",
+			in: dict.WordInfo{Phonetic: "KAT", Phonetics: []dict.Phonetic{
+				{Text: "KAT"},
+				{Text: "/kæt/"},
+			}},
+			want: dict.WordInfo{Phonetics: []dict.Phonetic{
+				{Text: "/kæt/"},
+			}},
+		},
+		{
+			name: "keeps audio of respellings",
+			in: dict.WordInfo{Phonetic: "KAT", Phonetics: []dict.Phonetic{
+				{Text: "KAT", Audio: "cat.mp3"},
+			}},
+			want: dict.WordInfo{Phonetics: []dict.Phonetic{
+				{Audio: "cat.mp3"},
+			}},
+		},
+		{
+			name: "no phonetics left",
+			in: dict.WordInfo{Phonetic: "KAT", Phonetics: []dict.Phonetic{
+				{Text: "KAT"},
+			}},
+			want: dict.WordInfo{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := onlyIPA(tt.in)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("onlyIPA() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleSearchResultPhonetics(t *testing.T) {
+	tests := []struct {
+		name    string
+		ipaOnly bool
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "labeled",
+			want: []string{"Phonetic Spelling: /kæt/ (IPA)", "Other Spellings: KAT (respelling)"},
+		},
+		{
+			name:    "IPA only",
+			ipaOnly: true,
+			want:    []string{"Phonetic Spelling: /kæt/ (IPA)"},
+			notWant: []string{"KAT", "Other Spellings"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wordInfo := testWordInfo()
+			wordInfo.Phonetics = []dict.Phonetic{{Text: "/kæt/"}, {Text: "KAT"}}
+
+			var buf bytes.Buffer
+			var err error
+
+			out := captureStdout(t, func() {
+				err = handleSearchResult(tablewriter.NewWriter(&buf), "cat", []dict.WordInfo{wordInfo}, nil, t.TempDir(), searchOptions{columns: defaultColumns, ipaOnly: tt.ipaOnly})
+			})
+
+			if err != nil {
+				t.Fatalf("handleSearchResult() error = %v", err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output does not show %q:\n%s", want, out)
+				}
+			}
+
+			for _, notWant := range tt.notWant {
+				if strings.Contains(out, notWant) {
+					t.Errorf("output shows %q:\n%s", notWant, out)
+				}
+			}
+		})
+	}
+}
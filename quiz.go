@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"wordef/dict"
+)
+
+const defaultQuizQuestions = 10
+
+// quizClue returns the first definition of a word with the word itself
+// blanked out, ok is false for words without definitions.
+func quizClue(wordInfo dict.WordInfo) (clue string, ok bool) {
+	hide := regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(wordInfo.Word) + `\b`)
+
+	for _, m := range wordInfo.Meanings {
+		if len(m.Definitions) > 0 {
+			definition := hide.ReplaceAllString(m.Definitions[0].Definition, "____")
+			return fmt.Sprintf("(%s) %s", m.PartOfSpeech, definition), true
+		}
+	}
+
+	return "", false
+}
+
+// handleQuizCommand asks for the saved word matching a definition, up to
+// questions times without repeating a word, and keeps score.
+func handleQuizCommand(cacheDir string, questions int, opts searchOptions) error {
+	words, err := dict.CachedWords(cacheDir)
+
+	if err != nil {
+		return err
+	}
+
+	if len(words) == 0 {
+		return errEmptyCache
+	}
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	r.Shuffle(len(words), func(i, j int) {
+		words[i], words[j] = words[j], words[i]
+	})
+
+	questions = min(questions, len(words))
+
+	searchOpts := opts.SearchOptions
+	searchOpts.Offline = true
+	searchOpts.CountsDir = ""
+	searchOpts.HistoryDir = ""
+
+	fmt.Printf("Guess the word from its definition, %d questions, an empty answer skips and quit stops\n", questions)
+
+	scanner := bufio.NewScanner(os.Stdin)
+	asked := 0
+	score := 0
+
+	for _, word := range words {
+		if asked == questions {
+			break
+		}
+
+		resp, err := dict.Search(context.Background(), word, cacheDir, searchOpts)
+
+		if err != nil || len(resp) == 0 {
+			logger.Debug("Skipping quiz word", "word", word, "reason", err)
+			continue
+		}
+
+		clue, ok := quizClue(resp[0])
+
+		if !ok {
+			continue
+		}
+
+		asked++
+
+		fmt.Printf("\n%d/%d: %s\n> ", asked, questions, clue)
+
+		if !scanner.Scan() {
+			fmt.Println()
+			break
+		}
+
+		answer := strings.TrimSpace(scanner.Text())
+
+		if answer == "quit" {
+			asked--
+			break
+		}
+
+		if dict.NormalizeWord(answer) == dict.NormalizeWord(word) {
+			score++
+			fmt.Println("Correct!")
+		} else {
+			fmt.Printf("The word was '%s'\n", word)
+		}
+	}
+
+	fmt.Printf("\nScore: %d/%d\n", score, asked)
+
+	return scanner.Err()
+}
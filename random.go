@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"time"
+
+	"wordef/dict"
+)
+
+var errEmptyCache = errors.New("No saved words yet, look up some words first with: wordef {word}")
+
+var wordOfTheDayWords = []string{
+	"serendipity", "ephemeral", "eloquent", "ubiquitous", "sonder",
+	"petrichor", "mellifluous", "quixotic", "halcyon", "luminous",
+	"resilience", "sanguine", "wanderlust", "ineffable", "zenith",
+	"aplomb", "cogent", "ebullient", "gregarious", "laconic",
+	"nonchalant", "panacea", "reverie", "sagacious", "tenacious",
+	"verisimilitude", "whimsical", "zealous", "bucolic", "candor",
+}
+
+// wordOfTheDay picks a word from the cache, or the built-in list when the
+// cache is empty, seeded by the date so it is stable for the whole day.
+func wordOfTheDay(cacheDir string, now time.Time) (string, error) {
+	words, err := dict.CachedWords(cacheDir)
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(words) == 0 {
+		words = wordOfTheDayWords
+	}
+
+	words = slices.Sorted(slices.Values(words))
+
+	year, month, day := now.Date()
+	seed := int64(year*10000 + int(month)*100 + day)
+
+	r := rand.New(rand.NewSource(seed))
+
+	return words[r.Intn(len(words))], nil
+}
+
+func randomCachedWord(cacheDir string) (string, error) {
+	words, err := dict.CachedWords(cacheDir)
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(words) == 0 {
+		return "", errEmptyCache
+	}
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	return words[r.Intn(len(words))], nil
+}
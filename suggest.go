@@ -0,0 +1,76 @@
+package main
+
+import (
+	"sort"
+
+	"wordef/dict"
+)
+
+const (
+	maxSuggestionDistance = 2
+	maxSuggestions        = 3
+)
+
+func levenshtein(a, b string) int {
+	ra := []rune(a)
+	rb := []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// suggestClosest returns up to three cached words within an edit distance of
+// two from word, closest first.
+func suggestClosest(word string, cachedWords []string) []string {
+	word = dict.NormalizeWord(word)
+
+	distances := make(map[string]int)
+	var suggestions []string
+
+	for _, cached := range cachedWords {
+		distance := levenshtein(word, dict.NormalizeWord(cached))
+
+		if distance == 0 || distance > maxSuggestionDistance {
+			continue
+		}
+
+		distances[cached] = distance
+		suggestions = append(suggestions, cached)
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if distances[suggestions[i]] != distances[suggestions[j]] {
+			return distances[suggestions[i]] < distances[suggestions[j]]
+		}
+
+		return suggestions[i] < suggestions[j]
+	})
+
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+
+	return suggestions
+}
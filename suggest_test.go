@@ -0,0 +1,58 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"cat", "", 3},
+		{"", "cat", 3},
+		{"cat", "cat", 0},
+		{"cat", "cut", 1},
+		{"cat", "cats", 1},
+		{"cat", "at", 1},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"café", "cafe", 1},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+
+		if got := levenshtein(tt.b, tt.a); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestClosest(t *testing.T) {
+	tests := []struct {
+		name   string
+		word   string
+		cached []string
+		want   []string
+	}{
+		{"nothing cached", "cat", nil, nil},
+		{"closest first", "catt", []string{"cattle", "cat", "chat"}, []string{"cat", "cattle", "chat"}},
+		{"ties sorted", "bat", []string{"rat", "cat", "hat"}, []string{"cat", "hat", "rat"}},
+		{"at most three", "cat", []string{"bat", "cab", "car", "cut", "hat"}, []string{"bat", "cab", "car"}},
+		{"too far", "cat", []string{"dog", "horse"}, nil},
+		{"exact match skipped", "Cat", []string{"cat", "cot"}, []string{"cot"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suggestClosest(tt.word, tt.cached); !slices.Equal(got, tt.want) {
+				t.Errorf("suggestClosest(%q, %v) = %v, want %v", tt.word, tt.cached, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+func isVowel(r rune, first bool) bool {
+	return strings.ContainsRune("aeiou", r) || (r == 'y' && !first)
+}
+
+// syllableCount estimates the syllables of a word by counting groups of
+// vowels, dropping a silent final "e" and a silent "ed" ending. English
+// spelling has too many exceptions for this to always be right, "poem" or
+// "naive" are counted as one syllable short for example, but it is close
+// enough for most words. Phrases are counted word by word, apostrophes do
+// not split a word.
+func syllableCount(word string) int {
+	count := 0
+
+	for _, w := range strings.FieldsFunc(strings.ToLower(word), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\'' && r != '’'
+	}) {
+		count += wordSyllables([]rune(w))
+	}
+
+	return count
+}
+
+func wordSyllables(w []rune) int {
+	count := 0
+	inVowelGroup := false
+
+	for i, r := range w {
+		vowel := isVowel(r, i == 0)
+
+		if vowel && !inVowelGroup {
+			count++
+		}
+
+		inVowelGroup = vowel
+	}
+
+	n := len(w)
+
+	if n > 2 && count > 1 {
+		last, beforeLast := w[n-1], w[n-2]
+
+		switch {
+		case last == 'e' && beforeLast == 'l' && !isVowel(w[n-3], false):
+			// "table" and "little" keep their final syllable.
+		case last == 'e' && !isVowel(beforeLast, false):
+			count--
+		case last == 'd' && beforeLast == 'e' && !strings.ContainsRune("td", w[n-3]) && !isVowel(w[n-3], false):
+			count--
+		}
+	}
+
+	return max(count, 1)
+}
+
+// letterCount counts the letters of a word, ignoring spaces, hyphens and
+// other punctuation.
+func letterCount(word string) int {
+	count := 0
+
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,58 @@
+package main
+
+import "testing"
+
+func TestSyllableCount(t *testing.T) {
+	tests := []struct {
+		word string
+		want int
+	}{
+		{"cat", 1},
+		{"a", 1},
+		{"Dog", 1},
+		{"make", 1},
+		{"table", 2},
+		{"little", 2},
+		{"happy", 2},
+		{"yellow", 2},
+		{"walked", 1},
+		{"wanted", 2},
+		{"needed", 2},
+		{"banana", 3},
+		{"beautiful", 3},
+		{"serendipity", 5},
+		{"ice cream", 2},
+		{"mother-in-law", 4},
+		{"don't", 1},
+		{"they’re", 1},
+		// Known misses of the heuristic, kept so changes to it are noticed.
+		{"poem", 1},
+		{"naive", 1},
+	}
+
+	for _, tt := range tests {
+		if got := syllableCount(tt.word); got != tt.want {
+			t.Errorf("syllableCount(%q) = %d, want %d", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestLetterCount(t *testing.T) {
+	tests := []struct {
+		word string
+		want int
+	}{
+		{"cat", 3},
+		{"ice cream", 8},
+		{"mother-in-law", 11},
+		{"don't", 4},
+		{"café", 4},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		if got := letterCount(tt.word); got != tt.want {
+			t.Errorf("letterCount(%q) = %d, want %d", tt.word, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"strconv"
+
+	"golang.org/x/term"
+)
+
+const defaultTerminalWidth = 80
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// terminalWidth returns the width of the terminal stdout is attached to,
+// then $COLUMNS, falling back to 80 columns.
+func terminalWidth() int {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+
+	if err == nil && width > 0 {
+		return width
+	}
+
+	columns, err := strconv.Atoi(os.Getenv("COLUMNS"))
+
+	if err == nil && columns > 0 {
+		return columns
+	}
+
+	return defaultTerminalWidth
+}
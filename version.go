@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// version and commit are set at build time, e.g.
+// go build -ldflags "-X main.version=1.2.3 -X main.commit=$(git rev-parse --short HEAD)"
+var (
+	version = "dev"
+	commit  = ""
+)
+
+func userAgent() string {
+	return "wordef/" + version
+}
+
+// buildCommit returns the commit set with -ldflags, falling back to the
+// revision recorded by the Go toolchain.
+func buildCommit() string {
+	if commit != "" {
+		return commit
+	}
+
+	info, ok := debug.ReadBuildInfo()
+
+	if ok {
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" {
+				return s.Value
+			}
+		}
+	}
+
+	return "unknown"
+}
+
+func handleVersionCommand() {
+	fmt.Printf("wordef %s\n", version)
+	fmt.Printf("commit: %s\n", buildCommit())
+	fmt.Printf("go: %s\n", runtime.Version())
+}
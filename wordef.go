@@ -1,250 +1,2294 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"io/fs"
-	"log"
-	"net/http"
+	"log/slog"
+	"maps"
 	"os"
-	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/olekukonko/tablewriter"
+
+	"wordef/datamuse"
+	"wordef/dict"
 )
 
-type WordInfo struct {
-	Word      string `json:"word"`
-	Phonetic  string `json:"phonetic"`
-	Phonetics []struct {
-		Text  string `json:"text"`
-		Audio string `json:"audio,omitempty"`
-	} `json:"phonetics"`
-	Origin   string `json:"origin"`
-	Meanings []struct {
-		PartOfSpeech string `json:"partOfSpeech"`
-		Definitions  []struct {
-			Definition string `json:"definition"`
-			Example    string `json:"example"`
-			Synonyms   []any  `json:"synonyms"`
-			Antonyms   []any  `json:"antonyms"`
-		} `json:"definitions"`
-	} `json:"meanings"`
+var logger = newLogger(slog.LevelWarn)
+
+func newLogger(level slog.Level) *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+
+			return a
+		},
+	}))
+}
+
+func getDurationEnv(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+
+	if err != nil || duration < 0 {
+		logger.Warn("Invalid environment variable, using default", "name", name, "value", value, "default", fallback)
+		return fallback
+	}
+
+	return duration
+}
+
+func getIntEnv(name string, fallback int) int {
+	value := os.Getenv(name)
+
+	if value == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(value)
+
+	if err != nil || n < 0 {
+		logger.Warn("Invalid environment variable, using default", "name", name, "value", value, "default", fallback)
+		return fallback
+	}
+
+	return n
+}
+
+// getCacheDir returns the cache directory given by --cache-dir, then
+// WORDEF_CACHE_DIR, then the config file, falling back to the default app
+// directory. The directory is returned even when it cannot be created.
+func getCacheDir(flagValue, configValue string) (string, error) {
+	dir := flagValue
+
+	if dir == "" {
+		dir = os.Getenv("WORDEF_CACHE_DIR")
+	}
+
+	if dir == "" {
+		dir = configValue
+	}
+
+	if dir == "" {
+		return defaultCacheDir()
+	}
+
+	err := os.MkdirAll(dir, dict.DirPerm)
+
+	if err != nil {
+		return dir, fmt.Errorf("Failed to create cache directory: %w", err)
+	}
+
+	return dir, nil
+}
+
+// defaultCacheDir returns the cache directory in the user cache directory,
+// first moving the words older versions saved in the config directory.
+func defaultCacheDir() (string, error) {
+	dir, err := dict.CacheDir()
+
+	if err != nil {
+		return dir, err
+	}
+
+	oldDir, err := dict.ConfigDir()
+
+	if err != nil {
+		return dir, nil
+	}
+
+	moved, err := dict.MigrateCache(oldDir, dir)
+
+	if err != nil {
+		logger.Warn("Saved words not moved to the cache directory", "from", oldDir, "to", dir, "reason", err)
+	} else if moved > 0 {
+		logger.Info("Moved saved words to the cache directory", "from", oldDir, "to", dir, "files", moved)
+	}
+
+	return dir, nil
+}
+
+func configureFromEnv() {
+	dict.HTTPClient.Timeout = getDurationEnv("WORDEF_TIMEOUT", dict.DefaultTimeout)
+	dict.CacheTTL = getDurationEnv("WORDEF_CACHE_TTL", 0)
+	dict.MaxAttempts = getIntEnv("WORDEF_RETRIES", dict.DefaultMaxAttempts)
+	dict.Compress = os.Getenv("WORDEF_CACHE_COMPRESS") == "1"
+	dict.CacheMax = getIntEnv("WORDEF_CACHE_MAX", 0)
+	dict.Rate = getIntEnv("WORDEF_RATE", dict.DefaultRate)
+	dict.HistoryMax = getIntEnv("WORDEF_HISTORY_MAX", dict.DefaultHistoryMax)
+
+	negativeTtl := os.Getenv("WORDEF_CACHE_NEGATIVE")
+
+	if negativeTtl == "1" {
+		dict.NegativeTTL = dict.DefaultNegativeTTL
+	} else if negativeTtl != "" {
+		dict.NegativeTTL = getDurationEnv("WORDEF_CACHE_NEGATIVE", 0)
+	}
+
+	baseUrl := os.Getenv("WORDEF_API_URL")
+
+	if baseUrl != "" {
+		if !strings.HasSuffix(baseUrl, "/") {
+			baseUrl += "/"
+		}
+
+		dict.BaseURL = baseUrl
+	}
+
+	dict.UserAgent = os.Getenv("WORDEF_USER_AGENT")
+
+	if dict.UserAgent == "" {
+		dict.UserAgent = userAgent()
+	}
+
+	datamuse.HTTPClient = dict.HTTPClient
+	datamuse.UserAgent = dict.UserAgent
+
+	datamuseUrl := os.Getenv("WORDEF_DATAMUSE_URL")
+
+	if datamuseUrl != "" {
+		datamuse.BaseURL = datamuseUrl
+	}
+
+	wordsApiUrl := os.Getenv("WORDEF_WORDSAPI_URL")
+
+	if wordsApiUrl != "" {
+		dict.WordsAPIURL = wordsApiUrl
+	}
+}
+
+func confirm(prompt string) bool {
+	fmt.Print(prompt, " [y/N] ")
+
+	reader := bufio.NewReader(os.Stdin)
+	answer, _ := reader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	return answer == "y" || answer == "yes"
+}
+
+// displayWord capitalizes a lowercase word for display. Words that already
+// contain capitals, such as "iPhone" or "NASA", are shown as they are.
+func displayWord(s string) string {
+	if len(s) == 0 || strings.ContainsFunc(s, unicode.IsUpper) {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+func anyToStrings(values []any) []string {
+	var result []string
+
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
+
+// uniqueSorted trims values and returns them sorted, without blanks and
+// without duplicates that only differ in case.
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(values))
+
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		key := strings.ToLower(v)
+
+		if v == "" || seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		result = append(result, v)
+	}
+
+	sortWords(result)
+
+	return result
+}
+
+// collectRelated gathers the synonyms, or antonyms, listed under every
+// meaning and definition of a word.
+func collectRelated(wordInfo dict.WordInfo, antonyms bool) []string {
+	var related []string
+
+	for _, m := range wordInfo.Meanings {
+		if antonyms {
+			related = append(related, anyToStrings(m.Antonyms)...)
+		} else {
+			related = append(related, anyToStrings(m.Synonyms)...)
+		}
+
+		for _, d := range m.Definitions {
+			if antonyms {
+				related = append(related, anyToStrings(d.Antonyms)...)
+			} else {
+				related = append(related, anyToStrings(d.Synonyms)...)
+			}
+		}
+	}
+
+	return uniqueSorted(related)
+}
+
+func collectSynonyms(wordInfo dict.WordInfo) []string {
+	return collectRelated(wordInfo, false)
+}
+
+func collectAntonyms(wordInfo dict.WordInfo) []string {
+	return collectRelated(wordInfo, true)
+}
+
+func filterMeanings(meanings []dict.Meaning, partsOfSpeech []string) []dict.Meaning {
+	var filtered []dict.Meaning
+
+	for _, m := range meanings {
+		if slices.Contains(partsOfSpeech, strings.ToLower(m.PartOfSpeech)) {
+			filtered = append(filtered, m)
+		}
+	}
+
+	return filtered
+}
+
+func parsePartsOfSpeech(value string) []string {
+	var parts []string
+
+	for _, part := range strings.Split(value, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return parts
+}
+
+const minDefinitionWidth = 20
+
+// definitionColumnWidth returns how wide the Definition column can be for
+// the table to fit in width columns, the POS column and the borders take
+// up the rest.
+func definitionColumnWidth(width int, meanings []dict.Meaning) int {
+	posWidth := len("POS")
+
+	for _, m := range meanings {
+		posWidth = max(posWidth, len(m.PartOfSpeech))
+	}
+
+	// "| " + pos + " | " + definition + " |"
+	return max(width-posWidth-7, minDefinitionWidth)
+}
+
+// autoWidth is the --width default, wrapping text to the terminal width.
+const autoWidth = -1
+
+// setColumnWidth wraps table cells at the --width given by the user, at
+// fitWidth when it was not given, or not at all for a width of 0.
+func setColumnWidth(table *tablewriter.Table, width, fitWidth int) {
+	if width == 0 {
+		table.SetAutoWrapText(false)
+		return
+	}
+
+	if width == autoWidth {
+		width = fitWidth
+	}
+
+	table.SetColWidth(width)
+}
+
+// definitionColumns are the columns --columns can pick from.
+var definitionColumns = []string{"pos", "definition", "example", "synonyms", "antonyms"}
+
+var defaultColumns = []string{"pos", "definition"}
+
+// parseColumns reads a comma separated --columns value, keeping its order.
+func parseColumns(value string) ([]string, error) {
+	var columns []string
+
+	for _, column := range strings.Split(value, ",") {
+		column = strings.ToLower(strings.TrimSpace(column))
+
+		if column == "" {
+			continue
+		}
+
+		if !slices.Contains(definitionColumns, column) {
+			return nil, fmt.Errorf("Unknown column %q, expected any of: %s", column, strings.Join(definitionColumns, ", "))
+		}
+
+		columns = append(columns, column)
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("--columns expects at least one of: %s", strings.Join(definitionColumns, ", "))
+	}
+
+	return columns, nil
+}
+
+// definitionCell returns the text of one --columns cell, examples are only
+// shown under the definition when there is no example column.
+func definitionCell(column, pos string, number int, d dict.Definition, opts searchOptions) string {
+	switch column {
+	case "pos":
+		return pos
+	case "definition":
+		definition := fmt.Sprintf("%d. %s", number, d.Definition)
+
+		if !opts.noExamples && d.Example != "" && !slices.Contains(opts.columns, "example") {
+			definition += fmt.Sprintf("\n   \"%s\"", d.Example)
+		}
+
+		return definition
+	case "example":
+		if opts.noExamples || d.Example == "" {
+			return ""
+		}
+
+		return fmt.Sprintf("\"%s\"", d.Example)
+	case "synonyms":
+		return strings.Join(uniqueSorted(anyToStrings(d.Synonyms)), ", ")
+	case "antonyms":
+		return strings.Join(uniqueSorted(anyToStrings(d.Antonyms)), ", ")
+	}
+
+	return ""
+}
+
+// wrapCell wraps each line of a table cell at width, keeping the indent of
+// the line on every line it wraps into. A width of 0 leaves text unwrapped.
+func wrapCell(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+
+	var wrapped []string
+
+	for _, line := range strings.Split(text, "\n") {
+		content := strings.TrimLeft(line, " ")
+		indent := line[:len(line)-len(content)]
+
+		lines, _ := tablewriter.WrapString(content, max(width-len(indent), 1))
+
+		for _, l := range lines {
+			wrapped = append(wrapped, indent+l)
+		}
+	}
+
+	return strings.Join(wrapped, "\n")
+}
+
+func renderDefinitionsTable(table *tablewriter.Table, wordInfo dict.WordInfo, opts searchOptions) {
+	if len(opts.columns) == 0 {
+		opts.columns = defaultColumns
+	}
+
+	header := make([]string, len(opts.columns))
+	colors := make([]tablewriter.Colors, len(opts.columns))
+	textColumns := 0
+
+	for i, column := range opts.columns {
+		header[i] = column
+
+		if column == "pos" {
+			colors[i] = tablewriter.Colors{tablewriter.FgCyanColor}
+		} else {
+			textColumns++
+		}
+	}
+
+	fitWidth := definitionColumnWidth(terminalWidth(), wordInfo.Meanings)
+
+	if textColumns > 1 {
+		// Each extra column also takes up its " | " border.
+		fitWidth = max((fitWidth-3*(textColumns-1))/textColumns, minDefinitionWidth)
+	}
+
+	// Cells are wrapped here rather than by the table, which would drop the
+	// indent of examples and put a blank line above them.
+	wrapWidth := opts.width
+
+	if wrapWidth == autoWidth {
+		wrapWidth = fitWidth
+	}
+
+	table.SetHeader(header)
+	table.SetRowLine(true)
+	table.SetAutoWrapText(false)
+
+	if opts.color {
+		table.SetColumnColor(colors...)
+	}
+
+	rows := 0
+	omitted := 0
+
+	var clamped []string
+
+	for _, v := range wordInfo.Meanings {
+		definitions := v.Definitions
+		first := 0
+
+		if opts.sense > 0 && len(definitions) > 0 {
+			index, ok := senseIndex(len(definitions), opts.sense)
+
+			if !ok {
+				clamped = append(clamped, fmt.Sprintf("%s has only %d", v.PartOfSpeech, len(definitions)))
+			}
+
+			definitions = definitions[index : index+1]
+			first = index
+		}
+
+		if opts.limit > 0 && len(definitions) > opts.limit {
+			definitions = definitions[:opts.limit]
+		}
+
+		for i, d := range definitions {
+			if opts.definitionsCount > 0 && rows >= opts.definitionsCount {
+				omitted++
+				continue
+			}
+
+			rows++
+
+			pos := ""
+
+			if i == 0 {
+				pos = v.PartOfSpeech
+			}
+
+			row := make([]string, len(opts.columns))
+
+			for j, column := range opts.columns {
+				row[j] = wrapCell(definitionCell(column, pos, first+i+1, d, opts), wrapWidth)
+			}
+
+			table.Append(row)
+		}
+	}
+
+	table.Render()
+
+	if len(clamped) > 0 {
+		fmt.Printf("(%s, showing the last definition instead of sense %d)\n", strings.Join(clamped, ", "), opts.sense)
+	}
+
+	if omitted > 0 {
+		fmt.Printf("(+%d more, use -n 0 to see all)\n", omitted)
+	}
+}
+
+// senseIndex returns the index of the 1-based sense in count definitions,
+// clamped to the last definition, ok is false when it had to be clamped.
+func senseIndex(count, sense int) (index int, ok bool) {
+	if sense > count {
+		return count - 1, false
+	}
+
+	return sense - 1, true
+}
+
+// phoneticSpellings returns the primary phonetic spelling, falling back to
+// the first one in Phonetics, and the distinct spellings that differ from it.
+func phoneticSpellings(wordInfo dict.WordInfo) (primary string, others []string) {
+	primary = wordInfo.Phonetic
+
+	for _, p := range wordInfo.Phonetics {
+		if p.Text == "" || p.Text == primary || slices.Contains(others, p.Text) {
+			continue
+		}
+
+		if primary == "" {
+			primary = p.Text
+			continue
+		}
+
+		others = append(others, p.Text)
+	}
+
+	return primary, others
+}
+
+func renderShort(wordInfo dict.WordInfo, color bool, sense int) {
+	header := bold(displayWord(wordInfo.Word), color)
+	primary, _ := phoneticSpellings(wordInfo)
+
+	if primary != "" {
+		header += " " + primary
+	}
+
+	for _, m := range wordInfo.Meanings {
+		if len(m.Definitions) > 0 {
+			index, _ := senseIndex(len(m.Definitions), max(sense, 1))
+
+			fmt.Printf("%s (%s): %s\n", header, m.PartOfSpeech, m.Definitions[index].Definition)
+			return
+		}
+	}
+
+	fmt.Println(header)
+}
+
+func renderAudio(wordInfo dict.WordInfo, color bool) {
+	found := false
+
+	for _, p := range wordInfo.Phonetics {
+		if p.Audio == "" {
+			continue
+		}
+
+		found = true
+
+		audio := hyperlink(p.Audio, color)
+
+		if p.Text == "" {
+			fmt.Println(audio)
+		} else {
+			fmt.Println(p.Text, "->", audio)
+		}
+	}
+
+	if !found {
+		fmt.Println("No audio pronunciations available")
+	}
+}
+
+// jsonIndent indents JSON output, --json-compact empties it to print each
+// value on a single line.
+var jsonIndent = "  "
+
+func printJson(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", jsonIndent)
+	encoder.SetEscapeHTML(false)
+
+	err := encoder.Encode(v)
+
+	if err != nil {
+		return fmt.Errorf("Failed to encode JSON output: %w", err)
+	}
+
+	return nil
+}
+
+func sortWords(words []string) {
+	slices.SortFunc(words, func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+}
+
+const defaultPageSize = 50
+
+// renderCachedWordsTable shows one page of the saved words, a pageSize of 0
+// shows them all. Starred words are marked when there are favorites.
+func renderCachedWordsTable(table *tablewriter.Table, cachedWords []string, favorites []string, page, pageSize int) error {
+	if len(favorites) > 0 {
+		table.SetHeader([]string{"Saved Words", "Starred"})
+	} else {
+		table.SetHeader([]string{"Saved Words"})
+	}
+
+	sortWords(cachedWords)
+
+	total := len(cachedWords)
+	start, end := 0, total
+
+	if pageSize > 0 && total > pageSize {
+		pages := (total + pageSize - 1) / pageSize
+
+		if page > pages {
+			return fmt.Errorf("--page %d is past the last page, %d", page, pages)
+		}
+
+		start = (page - 1) * pageSize
+		end = min(start+pageSize, total)
+	}
+
+	for _, v := range cachedWords[start:end] {
+		row := []string{displayWord(v)}
+
+		if len(favorites) > 0 {
+			star := ""
+
+			if slices.Contains(favorites, dict.NormalizeWord(v)) {
+				star = "*"
+			}
+
+			row = append(row, star)
+		}
+
+		table.Append(row)
+	}
+
+	table.Render()
+
+	if end < total {
+		fmt.Printf("(showing %d-%d of %d, use --page %d)\n", start+1, end, total, page+1)
+	} else if start > 0 {
+		fmt.Printf("(showing %d-%d of %d)\n", start+1, end, total)
+	}
+
+	return nil
+}
+
+type searchOptions struct {
+	dict.SearchOptions
+	jsonOutput       bool
+	limit            int
+	noExamples       bool
+	originOnly       bool
+	color            bool
+	audioOnly        bool
+	play             bool
+	allPhonetics     bool
+	ipaOnly          bool
+	maxMeanings      int
+	partsOfSpeech    []string
+	short            bool
+	workers          int
+	definitionsCount int
+	quiet            bool
+	markdown         bool
+	csv              bool
+	anki             bool
+	full             bool
+	syllables        bool
+	grouped          bool
+	columns          []string
+	check            bool
+	lemmatize        bool
+	jsonNormalized   bool
+	width            int
+	jsonLines        bool
+	sense            int
+	page             int
+	pageSize         int
+	// dataDir holds the files shared by every language, such as favorites.
+	dataDir string
+}
+
+func lookupWord(word string, cacheDir string, opts searchOptions) ([]dict.WordInfo, error) {
+	resp, err := dict.Search(context.Background(), word, cacheDir, opts.SearchOptions)
+
+	if opts.lemmatize && errors.Is(err, dict.ErrWordNotFound) {
+		resp, err = lookupLemma(word, cacheDir, opts, err)
+	}
+
+	return checkLookup(word, resp, err)
+}
+
+func lookupWords(words []string, cacheDir string, opts searchOptions) []dict.Result {
+	results := dict.SearchAll(context.Background(), words, cacheDir, opts.SearchOptions, opts.workers)
+
+	for i, r := range results {
+		results[i] = checkResult(r, cacheDir, opts)
+	}
+
+	return results
+}
+
+// checkResult applies --lemmatize and checkLookup to a batch result.
+func checkResult(r dict.Result, cacheDir string, opts searchOptions) dict.Result {
+	if opts.lemmatize && errors.Is(r.Err, dict.ErrWordNotFound) {
+		r.Entries, r.Err = lookupLemma(r.Word, cacheDir, opts, r.Err)
+	}
+
+	r.Entries, r.Err = checkLookup(r.Word, r.Entries, r.Err)
+
+	return r
+}
+
+// lookupLemma looks up the base forms of a word that was not found, such as
+// "run" for "running", and returns notFound if none of them exist either.
+func lookupLemma(word string, cacheDir string, opts searchOptions, notFound error) ([]dict.WordInfo, error) {
+	for _, lemma := range dict.Lemmas(word) {
+		resp, err := dict.Search(context.Background(), lemma, cacheDir, opts.SearchOptions)
+
+		if err == nil && len(resp) > 0 {
+			fmt.Fprintf(os.Stderr, "Showing results for '%s'\n", lemma)
+			return resp, nil
+		}
+
+		if !errors.Is(err, dict.ErrWordNotFound) {
+			logger.Debug("Lemma lookup failed", "word", word, "lemma", lemma, "reason", err)
+		}
+	}
+
+	return nil, notFound
+}
+
+func checkLookup(word string, resp []dict.WordInfo, err error) ([]dict.WordInfo, error) {
+	var notFound *dict.WordNotFoundError
+
+	if errors.As(err, &notFound) {
+		return nil, notFound
+	}
+
+	var noResults *dict.NoResultsError
+
+	if errors.As(err, &noResults) {
+		return nil, noResults
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to search for word %s: %w", word, err)
+	}
+
+	if len(resp) == 0 {
+		return nil, fmt.Errorf("No results for '%s'", word)
+	}
+
+	return resp, nil
+}
+
+func withSuggestions(err error, word string, cacheDir string) error {
+	cachedWords, cacheErr := dict.CachedWords(cacheDir)
+
+	if cacheErr != nil {
+		return err
+	}
+
+	suggestions := suggestClosest(word, cachedWords)
+
+	if len(suggestions) == 0 {
+		return err
+	}
+
+	return fmt.Errorf("%w\nDid you mean: %s", err, strings.Join(suggestions, ", "))
+}
+
+func handleSearchCommand(table *tablewriter.Table, word string, cacheDir string, opts searchOptions) error {
+	resp, err := lookupWord(word, cacheDir, opts)
+
+	return handleSearchResult(table, word, resp, err, cacheDir, opts)
+}
+
+func handleSearchResult(table *tablewriter.Table, word string, resp []dict.WordInfo, err error, cacheDir string, opts searchOptions) error {
+	if errors.Is(err, dict.ErrWordNotFound) {
+		return withSuggestions(err, word, cacheDir)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if opts.jsonOutput {
+		return printJson(jsonEntries(resp, opts))
+	}
+
+	wordInfo := resp[0]
+
+	if len(wordInfo.Meanings) == 0 {
+		return fmt.Errorf("No results for '%s'", word)
+	}
+
+	if len(opts.partsOfSpeech) > 0 {
+		wordInfo.Meanings = filterMeanings(wordInfo.Meanings, opts.partsOfSpeech)
+
+		if len(wordInfo.Meanings) == 0 {
+			return fmt.Errorf("No %s definitions found for '%s'", strings.Join(opts.partsOfSpeech, " or "), word)
+		}
+	}
+
+	if opts.ipaOnly {
+		wordInfo = onlyIPA(wordInfo)
+	}
+
+	var omittedMeanings int
+
+	wordInfo.Meanings, omittedMeanings = limitMeanings(wordInfo.Meanings, opts.maxMeanings)
+
+	if opts.play {
+		return playFirstAudio(wordInfo)
+	}
+
+	if opts.short {
+		renderShort(wordInfo, opts.color, opts.sense)
+		return nil
+	}
+
+	if opts.full {
+		renderFull(os.Stdout, wordInfo, opts.color)
+		printOmittedMeanings(omittedMeanings)
+		return nil
+	}
+
+	if opts.markdown {
+		renderMarkdown(os.Stdout, wordInfo)
+		return nil
+	}
+
+	if opts.allPhonetics {
+		primary, others := phoneticSpellings(wordInfo)
+
+		if primary == "" {
+			fmt.Println("No phonetic spellings available")
+			return nil
+		}
+
+		fmt.Println(primary)
+
+		for _, p := range others {
+			fmt.Println(p)
+		}
+
+		return nil
+	}
+
+	if opts.audioOnly {
+		renderAudio(wordInfo, opts.color)
+		return nil
+	}
+
+	if opts.originOnly {
+		if wordInfo.Origin == "" {
+			fmt.Printf("No origin available for '%s'\n", word)
+			return nil
+		}
+
+		fmt.Println(wordInfo.Origin)
+		return nil
+	}
+
+	if !opts.quiet {
+		renderHeader(wordInfo, opts.color, opts.syllables)
+	}
+
+	if opts.grouped {
+		renderGrouped(os.Stdout, wordInfo, opts)
+		printOmittedMeanings(omittedMeanings)
+		return nil
+	}
+
+	renderDefinitionsTable(table, wordInfo, opts)
+	printOmittedMeanings(omittedMeanings)
+
+	return nil
+}
+
+// limitMeanings keeps the first max parts of speech of a word, max <= 0
+// keeps them all.
+func limitMeanings(meanings []dict.Meaning, max int) (kept []dict.Meaning, omitted int) {
+	if max <= 0 || len(meanings) <= max {
+		return meanings, 0
+	}
+
+	return meanings[:max], len(meanings) - max
+}
+
+func printOmittedMeanings(omitted int) {
+	if omitted > 0 {
+		fmt.Printf("(+%d more parts of speech, use --max-meanings 0 to see all)\n", omitted)
+	}
+}
+
+// printMetrics writes the lookup summary of this run to stderr.
+func printMetrics(metrics *dict.Metrics) {
+	fmt.Fprintf(os.Stderr, "Cache hits: %d, API fetches: %d, Downloaded: %s\n",
+		metrics.CacheHits.Load(), metrics.APIFetches.Load(), formatBytes(metrics.BytesDownloaded.Load()))
+}
+
+// renderHeader prints the lines shown above the definitions table.
+func renderHeader(wordInfo dict.WordInfo, color, syllables bool) {
+	primary, others := phoneticSpellings(wordInfo)
+
+	fmt.Println("Word:", bold(displayWord(wordInfo.Word), color))
+
+	if primary == "" {
+		fmt.Println("Phonetic Spelling:")
+	} else {
+		fmt.Println("Phonetic Spelling:", labelPhonetic(primary))
+	}
+
+	if len(others) > 0 {
+		fmt.Println("Other Spellings:", strings.Join(labelPhonetics(others), ", "))
+	}
+
+	if wordInfo.Origin != "" {
+		fmt.Println("Origin:", wordInfo.Origin)
+	}
+
+	if syllables {
+		fmt.Println("Syllables:", syllableCount(wordInfo.Word), "(estimated)")
+		fmt.Println("Letters:", letterCount(wordInfo.Word))
+	}
+
+	fmt.Println()
+}
+
+// batchError summarizes the failed lookups of a batch, it matches
+// dict.ErrWordNotFound when every failure was an unknown word.
+type batchError struct {
+	errs  []error
+	total int
+}
+
+func (e *batchError) Error() string {
+	return fmt.Sprintf("%d of %d words could not be found", len(e.errs), e.total)
+}
+
+func (e *batchError) Is(target error) bool {
+	if target != dict.ErrWordNotFound {
+		return false
+	}
+
+	for _, err := range e.errs {
+		if !errors.Is(err, dict.ErrWordNotFound) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func handleSearchCommands(words []string, cacheDir string, opts searchOptions) error {
+	if opts.jsonLines {
+		return handleJsonLinesCommand(words, cacheDir, opts)
+	}
+
+	if opts.check {
+		return handleCheckCommand(words, cacheDir, opts)
+	}
+
+	if opts.csv {
+		return handleCsvCommand(words, cacheDir, opts)
+	}
+
+	if opts.anki {
+		return handleAnkiCommand(words, cacheDir, opts)
+	}
+
+	var errs []error
+
+	results := lookupWords(words, cacheDir, opts)
+
+	for i, r := range results {
+		if i > 0 && !opts.jsonOutput {
+			fmt.Println()
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+
+		err := handleSearchResult(table, r.Word, r.Entries, r.Err, cacheDir, opts)
+
+		if err != nil {
+			if len(words) == 1 {
+				return err
+			}
+
+			fmt.Fprintln(os.Stderr, err)
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return &batchError{errs: errs, total: len(words)}
+	}
+
+	return nil
+}
+
+func readWords(r io.Reader) (words []string, err error) {
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+
+	err = scanner.Err()
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read words: %w", err)
+	}
+
+	return words, nil
+}
+
+// forEachWordInfo looks up words and calls fn with the first entry of each
+// word found, filtered by --pos. Words that cannot be found are reported on
+// stderr and returned together as a batchError once every word is done.
+func forEachWordInfo(words []string, cacheDir string, opts searchOptions, fn func(dict.WordInfo) error) error {
+	var errs []error
+
+	for _, r := range lookupWords(words, cacheDir, opts) {
+		if r.Err != nil {
+			fmt.Fprintln(os.Stderr, r.Err)
+			errs = append(errs, r.Err)
+			continue
+		}
+
+		wordInfo := r.Entries[0]
+
+		if len(opts.partsOfSpeech) > 0 {
+			wordInfo.Meanings = filterMeanings(wordInfo.Meanings, opts.partsOfSpeech)
+		}
+
+		wordInfo.Meanings, _ = limitMeanings(wordInfo.Meanings, opts.maxMeanings)
+
+		err := fn(wordInfo)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(errs) > 0 {
+		return &batchError{errs: errs, total: len(words)}
+	}
+
+	return nil
+}
+
+func handleJsonBatchCommand(words []string, cacheDir string, opts searchOptions) error {
+	results := make(map[string]any)
+	var errs []error
+
+	for _, r := range lookupWords(words, cacheDir, opts) {
+		if r.Err != nil {
+			fmt.Fprintln(os.Stderr, r.Err)
+			errs = append(errs, r.Err)
+			continue
+		}
+
+		results[r.Word] = jsonEntries(r.Entries, opts)
+	}
+
+	err := printJson(results)
+
+	if err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return &batchError{errs: errs, total: len(words)}
+	}
+
+	return nil
+}
+
+func handleStdinCommand(cacheDir string, opts searchOptions) error {
+	words, err := readWords(os.Stdin)
+
+	if err != nil {
+		return err
+	}
+
+	if len(words) == 0 {
+		return errors.New("No words given on stdin")
+	}
+
+	if opts.jsonOutput && !opts.jsonLines {
+		return handleJsonBatchCommand(words, cacheDir, opts)
+	}
+
+	return handleSearchCommands(words, cacheDir, opts)
+}
+
+// handlePipedCommand looks up a single word piped into wordef without
+// arguments, as in echo cat | wordef, and shows the welcome message when
+// nothing was piped.
+func handlePipedCommand(cacheDir string, opts searchOptions) error {
+	words, err := readWords(os.Stdin)
+
+	if err != nil {
+		return err
+	}
+
+	if len(words) == 0 {
+		return handleWelcomeCommand(tablewriter.NewWriter(os.Stdout), cacheDir, opts)
+	}
+
+	if len(words) > 1 {
+		return errors.New("Several words were piped, use --stdin to look them all up")
+	}
+
+	return handleSearchCommands(words, cacheDir, opts)
+}
+
+func handleInteractiveCommand(cacheDir string, opts searchOptions) error {
+	fmt.Println("Type a word to look it up, :list to show saved words, quit to exit")
+
+	scanner := bufio.NewScanner(os.Stdin)
+
+	for {
+		fmt.Print("wordef> ")
+
+		if !scanner.Scan() {
+			fmt.Println()
+			return scanner.Err()
+		}
+
+		input := strings.TrimSpace(scanner.Text())
+
+		switch input {
+		case "":
+			continue
+		case "quit":
+			return nil
+		case ":list":
+			cachedWords, err := dict.CachedWords(cacheDir)
+
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				continue
+			}
+
+			renderCachedWordsTable(tablewriter.NewWriter(os.Stdout), cachedWords, nil, 1, 0)
+			continue
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+
+		err := handleSearchCommand(table, input, cacheDir, opts)
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+
+		fmt.Println()
+	}
+}
+
+func handleWordOfTheDayCommand(cacheDir string, opts searchOptions) error {
+	word, err := wordOfTheDay(cacheDir, time.Now())
+
+	if err != nil {
+		return fmt.Errorf("Failed to pick the word of the day: %w", err)
+	}
+
+	fmt.Println("Word of the day")
+	fmt.Println()
+
+	return handleSearchCommand(tablewriter.NewWriter(os.Stdout), word, cacheDir, opts)
+}
+
+func handleRandomCommand(cacheDir string, opts searchOptions) error {
+	word, err := randomCachedWord(cacheDir)
+
+	if errors.Is(err, errEmptyCache) {
+		fmt.Println(err)
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("Failed to pick a random word: %w", err)
+	}
+
+	return handleSearchCommand(tablewriter.NewWriter(os.Stdout), word, cacheDir, opts)
+}
+
+func handleRelatedWordsCommand(word string, cacheDir string, opts searchOptions, antonyms bool) error {
+	resp, err := lookupWord(word, cacheDir, opts)
+
+	if err != nil {
+		return err
+	}
+
+	label := "synonyms"
+
+	if antonyms {
+		label = "antonyms"
+	}
+
+	var related []string
+
+	for _, wordInfo := range resp {
+		if antonyms {
+			related = append(related, collectAntonyms(wordInfo)...)
+		} else {
+			related = append(related, collectSynonyms(wordInfo)...)
+		}
+	}
+
+	related = uniqueSorted(related)
+
+	if opts.jsonOutput {
+		return printJson(related)
+	}
+
+	if len(related) == 0 {
+		fmt.Printf("No %s found\n", label)
+		return nil
+	}
+
+	fmt.Println(strings.Join(related, ", "))
+
+	return nil
+}
+
+// handleRefreshCommand fetches every saved word from the API again,
+// replacing the saved definitions.
+func handleRefreshCommand(cacheDir string, dryRun bool, opts searchOptions) error {
+	words, err := dict.CachedWords(cacheDir)
+
+	if err != nil {
+		return err
+	}
+
+	if len(words) == 0 {
+		fmt.Println("No saved words to refresh")
+		return nil
+	}
+
+	sortWords(words)
+
+	if dryRun {
+		fmt.Printf("Would refresh %d words:\n", len(words))
+
+		for _, word := range words {
+			fmt.Println(word)
+		}
+
+		return nil
+	}
+
+	opts.Refresh = true
+	opts.CountsDir = ""
+	opts.HistoryDir = ""
+
+	var errs []error
+
+	for _, r := range lookupWords(words, cacheDir, opts) {
+		if r.Err != nil {
+			fmt.Fprintln(os.Stderr, r.Err)
+			errs = append(errs, r.Err)
+			continue
+		}
+
+		fmt.Println("Refreshed", r.Word)
+	}
+
+	fmt.Printf("Refreshed %d of %d saved words\n", len(words)-len(errs), len(words))
+
+	if len(errs) > 0 {
+		return &batchError{errs: errs, total: len(words)}
+	}
+
+	return nil
+}
+
+func handleAliasCommand(assignment string, aliasesDir string) error {
+	alias, word, ok := strings.Cut(assignment, "=")
+
+	if !ok {
+		return errors.New("--alias expects alias=word, e.g. --alias s=serendipity")
+	}
+
+	err := dict.SetAlias(aliasesDir, alias, word)
+
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(word) == "" {
+		fmt.Printf("Removed alias '%s'\n", dict.NormalizeWord(alias))
+	} else {
+		fmt.Printf("'%s' now stands for '%s'\n", dict.NormalizeWord(alias), dict.NormalizeWord(word))
+	}
+
+	return nil
+}
+
+func handleAliasesCommand(table *tablewriter.Table, aliasesDir string) error {
+	aliases, err := dict.Aliases(aliasesDir)
+
+	if err != nil {
+		return err
+	}
+
+	if len(aliases) == 0 {
+		fmt.Println("No aliases saved, add one with --alias alias=word")
+		return nil
+	}
+
+	names := slices.Collect(maps.Keys(aliases))
+	sortWords(names)
+
+	table.SetHeader([]string{"Alias", "Word"})
+
+	for _, name := range names {
+		table.Append([]string{name, aliases[name]})
+	}
+
+	table.Render()
+
+	return nil
+}
+
+func handleStarCommand(word string, cacheDir string, favoritesDir string) error {
+	added, err := dict.Star(favoritesDir, word)
+
+	if err != nil {
+		return err
+	}
+
+	word = dict.NormalizeWord(word)
+
+	if !added {
+		fmt.Printf("'%s' is already starred\n", word)
+		return nil
+	}
+
+	fmt.Printf("Starred '%s'\n", word)
+
+	if !dict.IsCached(word, cacheDir) {
+		fmt.Printf("'%s' is not saved yet, look it up to save its definitions\n", word)
+	}
+
+	return nil
+}
+
+func handleUnstarCommand(word string, favoritesDir string) error {
+	removed, err := dict.Unstar(favoritesDir, word)
+
+	if err != nil {
+		return err
+	}
+
+	word = dict.NormalizeWord(word)
+
+	if !removed {
+		fmt.Printf("'%s' is not starred, nothing to remove\n", word)
+		return nil
+	}
+
+	fmt.Printf("Unstarred '%s'\n", word)
+
+	return nil
+}
+
+func handleFavoritesCommand(favoritesDir string) error {
+	favorites, err := dict.Favorites(favoritesDir)
+
+	if err != nil {
+		return err
+	}
+
+	if len(favorites) == 0 {
+		fmt.Println("No starred words, star one with --star {word}")
+		return nil
+	}
+
+	for _, word := range favorites {
+		fmt.Println(word)
+	}
+
+	return nil
 }
 
-func getCacheDir() (string, error) {
-	dir, err := os.UserConfigDir()
+func handleDeleteCommand(word string, cacheDir string) error {
+	err := dict.DeleteFromCache(word, cacheDir)
+
+	if errors.Is(err, fs.ErrNotExist) {
+		fmt.Printf("'%s' is not in the cache, nothing to remove\n", word)
+		return nil
+	}
 
 	if err != nil {
-		return "", fmt.Errorf("Failed to get user config directory: %w", err)
+		return fmt.Errorf("Failed to delete word %s: %w", word, err)
 	}
 
-	path := filepath.Join(dir, "wordef")
+	fmt.Printf("Removed '%s' from cache\n", word)
+
+	return nil
+}
+
+func handleClearCommand(cacheDir string, force bool) error {
+	if !force && !confirm("Remove all saved words from "+cacheDir+"?") {
+		fmt.Println("Aborted, cache left untouched")
+		return nil
+	}
 
-	err = os.MkdirAll(path, os.ModePerm)
+	removed, err := dict.ClearCache(cacheDir)
 
 	if err != nil {
-		return "", fmt.Errorf("Failed to create app directory: %w", err)
+		return err
 	}
 
-	return path, nil
+	fmt.Printf("Removed %d saved words from cache\n", removed)
+
+	return nil
 }
 
-func saveToCache(word string, rawJson []byte, cacheDir string) error {
-	wordPath := path.Join(cacheDir, word+".json")
+func handleExportCommand(fileName string, cacheDir string) error {
+	entries, err := dict.ExportCache(cacheDir)
+
+	if err != nil {
+		return fmt.Errorf("Failed to export cache: %w", err)
+	}
 
-	_, err := os.Stat(wordPath)
+	rawJson, err := json.MarshalIndent(entries, "", "  ")
 
-	if err == nil {
-		return errors.New("Word already saved to file")
+	if err != nil {
+		return fmt.Errorf("Failed to encode export: %w", err)
 	}
 
-	err = os.WriteFile(wordPath, rawJson, os.ModePerm)
+	err = os.WriteFile(fileName, rawJson, 0o644)
 
 	if err != nil {
-		return fmt.Errorf("Failed to write cache file to app directory: %w", err)
+		return fmt.Errorf("Failed to write export file: %w", err)
 	}
 
+	fmt.Printf("Exported %d saved words to %s\n", len(entries), fileName)
+
 	return nil
 }
 
-func fetchFromCache(word, cacheDir string) (rawJson []byte, err error) {
-	wordPath := path.Join(cacheDir, word+".json")
+func handleImportCommand(fileName string, cacheDir string, overwrite bool) error {
+	rawJson, err := os.ReadFile(fileName)
 
-	_, err = os.Stat(wordPath)
+	if err != nil {
+		return fmt.Errorf("Failed to read import file: %w", err)
+	}
+
+	var entries map[string]json.RawMessage
+
+	err = json.Unmarshal(rawJson, &entries)
 
 	if err != nil {
-		return nil, fmt.Errorf("Word not found in cache: %w", err)
+		return fmt.Errorf("Failed to parse import file: %w", err)
 	}
 
-	rawJson, err = os.ReadFile(wordPath)
+	imported, skipped, invalid, err := dict.ImportCache(cacheDir, entries, overwrite)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to read cache file: %w", err)
+		return fmt.Errorf("Failed to import cache: %w", err)
+	}
+
+	fmt.Printf("Imported %d words from %s\n", imported, fileName)
+
+	if skipped > 0 {
+		fmt.Printf("Skipped %d words that were already saved, use --overwrite to replace them\n", skipped)
+	}
+
+	if invalid > 0 {
+		fmt.Printf("Skipped %d entries that are not valid words\n", invalid)
 	}
 
-	return rawJson, nil
+	return nil
 }
 
-func fetchFromApi(word string) (rawJson []byte, err error) {
-	url := "https://api.dictionaryapi.dev/api/v2/entries/en/" + word
+func formatBytes(n int64) string {
+	const unit = 1024
 
-	resp, err := http.Get(url)
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
 
-	if err != nil {
-		return nil, err
+	value := float64(n)
+	suffixes := []string{"KiB", "MiB", "GiB"}
+	i := -1
+
+	for value >= unit && i < len(suffixes)-1 {
+		value /= unit
+		i++
+	}
+
+	return fmt.Sprintf("%.1f %s", value, suffixes[i])
+}
+
+func renderCacheStatsTable(table *tablewriter.Table, stats dict.CacheStats) {
+	table.SetHeader([]string{"Statistic", "Value"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	table.Append([]string{"Saved words", strconv.Itoa(stats.Count)})
+	table.Append([]string{"Total size", formatBytes(stats.TotalBytes)})
+
+	if stats.Count > 0 {
+		const layout = "2006-01-02 15:04"
+
+		table.Append([]string{"Oldest", fmt.Sprintf("%s (%s)", stats.Oldest.Word, stats.Oldest.ModTime.Format(layout))})
+		table.Append([]string{"Newest", fmt.Sprintf("%s (%s)", stats.Newest.Word, stats.Newest.ModTime.Format(layout))})
 	}
 
-	defer resp.Body.Close()
+	table.Render()
+}
 
-	rawJson, err = io.ReadAll(resp.Body)
+func handleStatsCommand(table *tablewriter.Table, cacheDir string) error {
+	stats, err := dict.Stats(cacheDir)
 
 	if err != nil {
-		return nil, fmt.Errorf("Failed to read response body: %w", err)
+		return err
+	}
+
+	fmt.Println("Cache Directory:", cacheDir)
+
+	renderCacheStatsTable(table, stats)
+
+	return nil
+}
+
+func renderGrepTable(table *tablewriter.Table, matches []dict.Match) {
+	table.SetHeader([]string{"Word", "Definition"})
+	table.SetAutoMergeCellsByColumnIndex([]int{0})
+	table.SetRowLine(true)
+
+	for _, m := range matches {
+		table.Append([]string{m.Word, m.Definition})
+	}
+
+	table.Render()
+}
+
+func renderFrequentTable(table *tablewriter.Table, words []dict.WordCount) {
+	table.SetHeader([]string{"#", "Word", "Lookups"})
+
+	for i, w := range words {
+		table.Append([]string{strconv.Itoa(i + 1), w.Word, strconv.Itoa(w.Count)})
 	}
 
-	return rawJson, nil
+	table.Render()
 }
 
-func searchWord(word, cacheDir string) (parsed []WordInfo, err error) {
+const defaultFrequentCount = 10
 
-	rawJson, err := fetchFromCache(word, cacheDir)
+// countArg returns the count given as the only argument of a command such as
+// --history 20, or 0 when there is none.
+func countArg(command string, args []string) (n int, err error) {
+	if len(args) == 0 {
+		return 0, nil
+	}
 
-	if err != nil {
-		rawJson, err = fetchFromApi(word)
+	if len(args) > 1 {
+		return 0, fmt.Errorf("%s takes at most one count", command)
 	}
 
-	err = json.Unmarshal(rawJson, &parsed)
+	n, err = strconv.Atoi(args[0])
+
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s expects a count of at least 1, not %q", command, args[0])
+	}
+
+	return n, nil
+}
+
+func handleFrequentCommand(table *tablewriter.Table, countsDir string, n int) error {
+	if n == 0 {
+		n = defaultFrequentCount
+	}
+
+	words, err := dict.MostFrequent(countsDir, n)
 
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	if len(words) == 0 {
+		fmt.Println("No lookups recorded yet")
+		return nil
 	}
 
-	saveToCache(word, rawJson, cacheDir)
+	renderFrequentTable(table, words)
 
-	return parsed, nil
+	return nil
 }
 
-func getCachedWords(cacheDir string) (words []string, err error) {
-	err = filepath.WalkDir(cacheDir, func(s string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
+const defaultHistoryCount = 10
 
-		filePath := d.Name()
+func renderHistoryTable(table *tablewriter.Table, entries []dict.HistoryEntry) {
+	table.SetHeader([]string{"Time", "Word"})
 
-		if filepath.Ext(filePath) == ".json" {
-			fileName := filepath.Base(filePath)
-			fileNameNoExt := strings.Replace(fileName, ".json", "", 1)
+	for _, e := range entries {
+		table.Append([]string{e.Time.Local().Format("2006-01-02 15:04:05"), e.Word})
+	}
 
-			words = append(words, fileNameNoExt)
-		}
+	table.Render()
+}
 
-		return nil
-	})
+func handleHistoryCommand(table *tablewriter.Table, historyDir string, n int) error {
+	if n == 0 {
+		n = defaultHistoryCount
+	}
+
+	entries, err := dict.History(historyDir, n)
 
 	if err != nil {
-		return nil, fmt.Errorf("Failed to get cached words from cache directory: %w", err)
+		return err
 	}
 
-	return words, nil
+	if len(entries) == 0 {
+		fmt.Println("No lookups in the history yet")
+		return nil
+	}
+
+	renderHistoryTable(table, entries)
+
+	return nil
 }
 
-func capitalizeString(s string) string {
-	if len(s) == 0 {
-		return ""
+func handleGrepCommand(table *tablewriter.Table, term string, cacheDir string) error {
+	matches, err := dict.GrepCache(cacheDir, term)
+
+	if err != nil {
+		return fmt.Errorf("Failed to search saved words: %w", err)
 	}
-	r := []rune(s)
-	r[0] = unicode.ToUpper(r[0])
-	return string(r)
+
+	if len(matches) == 0 {
+		fmt.Printf("No saved definitions contain '%s'\n", term)
+		return nil
+	}
+
+	renderGrepTable(table, matches)
+
+	return nil
 }
 
-func renderDefinitionsTable(table *tablewriter.Table, wordInfo WordInfo) {
-	table.SetHeader([]string{"POS", "Definition"})
+func handleMeaningCommand(table *tablewriter.Table, phrase string, cacheDir string, defineTop bool, opts searchOptions) error {
+	candidates, err := datamuse.MeansLike(context.Background(), phrase, 10)
 
-	for _, v := range wordInfo.Meanings {
-		pos := v.PartOfSpeech
-		definition := v.Definitions[0].Definition
+	if err != nil {
+		return fmt.Errorf("Failed to find words meaning '%s': %w", phrase, err)
+	}
+
+	if len(candidates) == 0 {
+		fmt.Printf("No words found meaning '%s'\n", phrase)
+		return nil
+	}
+
+	table.SetHeader([]string{"Candidate Words"})
 
-		table.Append([]string{pos, definition})
+	for _, c := range candidates {
+		table.Append([]string{c.Word})
 	}
 
 	table.Render()
+
+	if !defineTop {
+		return nil
+	}
+
+	fmt.Println()
+
+	return handleSearchCommand(tablewriter.NewWriter(os.Stdout), candidates[0].Word, cacheDir, opts)
 }
 
-func renderCachedWordsTable(table *tablewriter.Table, cachedWords []string) {
-	table.SetHeader([]string{"Saved Words"})
+func renderGrid(words []string, width int) {
+	cellWidth := 0
 
-	for _, v := range cachedWords {
-		table.Append([]string{v})
+	for _, w := range words {
+		cellWidth = max(cellWidth, tablewriter.DisplayWidth(w)+2)
 	}
 
-	table.Render()
-}
+	columns := max(1, width/cellWidth)
 
-func handleSearchCommand(table *tablewriter.Table, word string, cacheDir string) error {
-	var resp []WordInfo
+	for i, w := range words {
+		if i%columns == columns-1 || i == len(words)-1 {
+			fmt.Println(w)
+		} else {
+			fmt.Print(w, strings.Repeat(" ", cellWidth-tablewriter.DisplayWidth(w)))
+		}
+	}
+}
 
-	resp, err := searchWord(word, cacheDir)
+func handleRhymesCommand(word string) error {
+	rhymes, err := datamuse.Rhymes(context.Background(), word)
 
 	if err != nil {
-		return fmt.Errorf("Failed to search for word %s: %w", word, err)
+		return fmt.Errorf("Failed to find rhymes for '%s': %w", word, err)
 	}
 
-	wordInfo := resp[0]
+	if len(rhymes) == 0 {
+		fmt.Printf("No rhymes found for '%s'\n", word)
+		return nil
+	}
 
-	if len(wordInfo.Meanings) == 0 {
-		return fmt.Errorf("Failed to search for word %s: %w", word, err)
+	renderGrid(rhymes, terminalWidth())
+
+	return nil
+}
+
+func handleListWordsCommand(cacheDir string) error {
+	cachedWords, err := dict.CachedWords(cacheDir)
+
+	if err != nil {
+		return err
 	}
 
-	fmt.Println("Word:", wordInfo.Word)
-	fmt.Println("Phonetic Spelling:", wordInfo.Phonetic)
-	fmt.Println()
+	sortWords(cachedWords)
 
-	renderDefinitionsTable(table, wordInfo)
+	for _, word := range cachedWords {
+		fmt.Println(word)
+	}
 
 	return nil
 }
 
-func handleWelcomeCommand(table *tablewriter.Table, cacheDir string) error {
+// printUsage prints the commands, exit codes and config file shared by the
+// welcome message and --help.
+func printUsage() {
 	fmt.Println("wordef is used to lookup the phonetic spelling and the different definitions of a word, depending on the part-of-speech (noun, verb, adjective).")
 	fmt.Println()
 	fmt.Println("Commands:")
 	fmt.Println("\twordef - shows this welcome message and shows a list of words searched and saved locally")
+	fmt.Println("\twordef --page N [--page-size M] - shows the Nth page of saved words on the welcome screen, 50 per page by default")
 	fmt.Println("\twordef {word} - displays a word's phonetic spelling and definitions. Searches either through a local cache or through an API")
+	fmt.Println("\twordef {word} {word}... - displays the definitions of several words in one go")
+	fmt.Println("\twordef --delete {word} - removes a word from the local cache")
+	fmt.Println("\twordef --clear [--force] - removes every saved word from the local cache, --force skips the confirmation")
+	fmt.Println("\twordef --limit N {word} - shows at most N definitions per part of speech")
+	fmt.Println("\twordef --max-meanings N {word} - shows at most N parts of speech, however many definitions each has")
+	fmt.Println("\twordef -n N {word} - shows at most N definitions in total, same as --definitions-count=N")
+	fmt.Println("\twordef --sense N {word} - only shows the Nth definition of each part of speech, or the last one when there are fewer")
+	fmt.Println("\twordef --no-examples {word} - hides the example sentences shown under definitions")
+	fmt.Println("\twordef --synonyms {word} - lists the synonyms of a word")
+	fmt.Println("\twordef --antonyms {word} - lists the antonyms of a word")
+	fmt.Println("\twordef --lang {code} {word} - searches the dictionary of another language, e.g. es or fr")
+	fmt.Println("\twordef --provider wordsapi {word} - searches WordsAPI instead of dictionaryapi.dev, with the API key from WORDEF_API_KEY")
+	fmt.Println("\twordef --no-cache {word} - fetches fresh definitions from the API without touching the local cache")
+	fmt.Println("\twordef --lemmatize {word} - looks up the base form of a word that is not found, e.g. run for running or cat for cats")
+	fmt.Println("\twordef --offline {word} - only looks words up in the local cache and fails for words not saved yet")
+	fmt.Println("\twordef --interactive - looks up words typed at a prompt, :list shows saved words and quit exits")
+	fmt.Println("\twordef --origin {word} - prints only the origin of a word")
+	fmt.Println("\twordef --stdin - looks up every word read from stdin, one per line")
+	fmt.Println("\techo {word} | wordef - looks up a single word piped into wordef")
+	fmt.Println("\twordef --color=auto|always|never {word} - controls colored output, auto colors only when writing to a terminal")
+	fmt.Println("\twordef --audio {word} - lists links to audio recordings of a word's pronunciation")
+	fmt.Println("\twordef --play {word} - plays the pronunciation of a word, WORDEF_AUDIO_PLAYER overrides the player command")
+	fmt.Println("\twordef --all-phonetics {word} - lists every phonetic spelling of a word, one per line")
+	fmt.Println("\twordef --open {word} - opens the web page of a word in your browser, WORDEF_OPEN_URL sets the page with {word} as a placeholder")
+	fmt.Println("\twordef --ipa-only {word} - shows only the phonetic spellings written in IPA, leaving out plain respellings")
+	fmt.Println("\twordef --wotd - shows the word of the day, picked from your saved words")
+	fmt.Println("\twordef --random - shows a random word from your saved words")
+	fmt.Println("\twordef --quiz [--questions N] - quizzes you on your saved words by showing definitions to guess, 10 questions by default")
+	fmt.Println("\twordef --verbose {word} - logs each step of the lookup, such as cache hits and API requests, to stderr")
+	fmt.Println("\twordef --metrics {words...} - prints how many lookups were cache hits or API fetches and the bytes downloaded to stderr")
+	fmt.Println("\twordef --refresh [--dry-run] - fetches every saved word from the API again, --dry-run only lists them")
+	fmt.Println("\twordef --export {file} - writes every saved word to a single JSON file")
+	fmt.Println("\twordef --import {file} [--overwrite] - saves the words of an exported file, --overwrite replaces words already saved")
+	fmt.Println("\twordef --stats - shows how many words are saved, the cache size and the oldest and newest saved words")
+	fmt.Println("\twordef --pos {pos,...} {word} - only shows the given parts of speech, e.g. --pos=verb")
+	fmt.Println("\twordef --compare {word} {word} - shows the first definition of each part of speech of two words side by side")
+	fmt.Println("\twordef --check {word}... - prints ok for a known word and reports unknown words, also works with --stdin")
+	fmt.Println("\twordef --csv {word}... - prints definitions as CSV with a header row, also works with --stdin")
+	fmt.Println("\twordef --anki {word}... - prints a tab separated Anki card per word, ready to import into a deck, also works with --stdin")
+	fmt.Println("\twordef --markdown {word} - prints a word as a Markdown section, e.g. to append it to your notes")
+	fmt.Println("\twordef --quiet {word} - prints only the definitions table, without the word, phonetic spelling and origin above it")
+	fmt.Println("\twordef --full {word} - prints phonetic spellings, origin, audio links, definitions, examples, synonyms and antonyms of a word")
+	fmt.Println("\twordef --columns=pos,definition,example {word} - picks the table columns and their order from pos, definition, example, synonyms and antonyms")
+	fmt.Println("\twordef --grouped {word} - prints numbered definitions under a heading per part of speech instead of a table")
+	fmt.Println("\twordef --syllables {word} - also shows the estimated syllable count and letter count of a word")
+	fmt.Println("\twordef --short {word} - prints a word, its phonetic spelling and its main definition on a single line")
+	fmt.Println("\twordef --cache-dir {dir} [word] - saves words in another directory, WORDEF_CACHE_DIR does the same")
+	fmt.Println("\twordef --width N {word} - wraps definitions at N characters instead of the terminal width, 0 disables wrapping")
+	fmt.Println("\twordef --timeout 3s {word} - waits at most this long for the dictionary API, overriding WORDEF_TIMEOUT")
+	fmt.Println("\twordef --workers N {word}... - looks up at most N words at the same time, 4 by default")
+	fmt.Println("\twordef --grep {term} - finds saved words whose definitions contain the term")
+	fmt.Println("\twordef --meaning \"{description}\" [--define-top] - finds words matching a description, --define-top also looks up the best match")
+	fmt.Println("\twordef --rhymes {word} - lists words that rhyme with a word")
+	fmt.Println("\twordef --star {word} - adds a word to your favorites, starred words are marked in the saved words table")
+	fmt.Println("\twordef --unstar {word} - removes a word from your favorites")
+	fmt.Println("\twordef --favorites - lists your favorite words")
+	fmt.Println("\twordef --alias {alias}={word} - makes alias stand for a word in lookups, {alias}= removes the alias")
+	fmt.Println("\twordef --aliases - lists the saved aliases")
+	fmt.Printf("\twordef --history [N] - lists your last N lookups, 10 by default, WORDEF_HISTORY_MAX sets how many lookups are kept, %d by default and 0 for all\n", dict.DefaultHistoryMax)
+	fmt.Println("\twordef --no-history {word} - looks up a word without adding it to the history, WORDEF_NO_HISTORY=1 does the same for every lookup")
+	fmt.Println("\twordef --frequent [N] - lists the N words you look up most often, 10 by default")
+	fmt.Println("\twordef --list-words - prints saved words one per line in alphabetical order, for use in scripts")
+	fmt.Println("\twordef --help, -h - prints this usage summary and every flag")
+	fmt.Println("\twordef --version, -v - prints the version, commit and Go version wordef was built with")
+	fmt.Println("\twordef completion bash|zsh - prints a shell completion script that completes saved words")
+	fmt.Println("\twordef --json [word] - prints the word's definitions, or the list of saved words, as JSON")
+	fmt.Println("\twordef --json-normalized {word} - prints the word as JSON with typed fields: word, phonetics and meanings with pos and definitions")
+	fmt.Println("\twordef --jsonl {word}... - prints one JSON object per word and line as each lookup finishes, also works with --stdin")
+	fmt.Println("\twordef --json-compact [word] - same as --json but without whitespace, for piping into other tools")
+	fmt.Println()
+	fmt.Println("Exit codes: 0 on success, 1 on errors, 2 when a word could not be found")
 	fmt.Println()
+
+	path, err := configPath()
+
+	if err == nil {
+		fmt.Println("Config File:", path)
+		fmt.Println("\tsets default \"color\", \"lang\", \"limit\" and \"cacheDir\" values as a JSON object")
+		fmt.Println("\tflags take precedence over environment variables, which take precedence over the config file")
+		fmt.Println()
+	}
+}
+
+func handleHelpCommand() {
+	printUsage()
+
+	fmt.Println("Flags:")
+	flag.CommandLine.SetOutput(os.Stdout)
+	flag.PrintDefaults()
+}
+
+func handleWelcomeCommand(table *tablewriter.Table, cacheDir string, opts searchOptions) error {
+	if opts.jsonOutput {
+		cachedWords, err := dict.CachedWords(cacheDir)
+
+		if err != nil {
+			return fmt.Errorf("Failed to get list of cached words")
+		}
+
+		if cachedWords == nil {
+			cachedWords = []string{}
+		}
+
+		return printJson(cachedWords)
+	}
+
+	printUsage()
+
 	fmt.Println("Cache Directory:", cacheDir)
 
-	cachedWords, err := getCachedWords(cacheDir)
+	cachedWords, err := dict.CachedWords(cacheDir)
 
 	if err != nil {
 		return fmt.Errorf("Failed to get list of cached words")
 	}
 
-	renderCachedWordsTable(table, cachedWords)
+	favorites, err := dict.Favorites(opts.dataDir)
 
-	return nil
+	if err != nil {
+		return err
+	}
+
+	return renderCachedWordsTable(table, cachedWords, favorites, opts.page, opts.pageSize)
+}
+
+// Exit codes, a lookup of an unknown word exits with exitNotFound so scripts
+// can tell it apart from other failures.
+const (
+	exitError    = 1
+	exitNotFound = 2
+)
+
+func exitWithError(err error) {
+	fmt.Fprintln(os.Stderr, err)
+
+	if errors.Is(err, dict.ErrWordNotFound) {
+		os.Exit(exitNotFound)
+	}
+
+	os.Exit(exitError)
 }
 
 func main() {
-	cacheDir, err := getCacheDir()
+	config, err := loadConfig()
+
+	// A broken config file must not stop --help, or fixing it, from working.
+	if err != nil {
+		logger.Warn("Config file ignored, using built-in defaults", "reason", err)
+	}
+
+	jsonOutput := flag.Bool("json", false, "print output as JSON")
+	jsonNormalized := flag.Bool("json-normalized", false, "print definitions as JSON with a simpler, stable schema")
+	jsonLines := flag.Bool("jsonl", false, "print one JSON object per word and line as soon as each word is looked up")
+	jsonCompact := flag.Bool("json-compact", false, "print output as JSON without whitespace, one value per line")
+	deleteWord := flag.String("delete", "", "remove a word from the cache")
+	clearAll := flag.Bool("clear", false, "remove all words from the cache")
+	force := flag.Bool("force", false, "skip confirmation prompts")
+	limit := flag.Int("limit", config.Limit, "maximum number of definitions shown per part of speech, 0 shows all")
+	maxMeanings := flag.Int("max-meanings", 0, "maximum number of parts of speech shown, 0 shows all")
+	var definitionsCount int
+	flag.IntVar(&definitionsCount, "definitions-count", 0, "maximum number of definitions shown in total, 0 shows all")
+	flag.IntVar(&definitionsCount, "n", 0, "shorthand for --definitions-count")
+	noExamples := flag.Bool("no-examples", false, "hide example sentences")
+	synonyms := flag.Bool("synonyms", false, "list the synonyms of a word")
+	antonyms := flag.Bool("antonyms", false, "list the antonyms of a word")
+	lang := flag.String("lang", config.Lang, "language code of the dictionary to search")
+	providerName := flag.String("provider", dict.DefaultProvider, "dictionary service to look words up in: "+strings.Join(dict.Providers, ", "))
+	interactive := flag.Bool("interactive", false, "look up words from a prompt until quit")
+	originOnly := flag.Bool("origin", false, "print only the origin of a word")
+	audioOnly := flag.Bool("audio", false, "list the pronunciation audio URLs of a word")
+	play := flag.Bool("play", false, "play the pronunciation of a word")
+	allPhonetics := flag.Bool("all-phonetics", false, "list every phonetic spelling of a word")
+	openWord := flag.String("open", "", "open the web page of a word in your browser")
+	ipaOnly := flag.Bool("ipa-only", false, "only show phonetic spellings written in IPA")
+	pos := flag.String("pos", "", "only show these comma separated parts of speech, e.g. noun,verb")
+	short := flag.Bool("short", false, "print a word and its main definition on one line")
+	colorMode := flag.String("color", config.Color, "colorize output: auto, always or never")
+	wotd := flag.Bool("wotd", false, "show the word of the day")
+	random := flag.Bool("random", false, "show a random saved word")
+	fromStdin := flag.Bool("stdin", false, "look up newline separated words read from stdin")
+	noCache := flag.Bool("no-cache", false, "neither read from nor save to the cache")
+	exportFile := flag.String("export", "", "write all saved words to a JSON file")
+	importFile := flag.String("import", "", "save the words of an exported JSON file")
+	overwrite := flag.Bool("overwrite", false, "replace saved words when importing")
+	stats := flag.Bool("stats", false, "show statistics about the cache")
+	cacheDirFlag := flag.String("cache-dir", "", "directory to save words in, overrides WORDEF_CACHE_DIR")
+	timeout := flag.Duration("timeout", 0, "how long to wait for an API response, e.g. 3s, overrides WORDEF_TIMEOUT")
+	workers := flag.Int("workers", dict.DefaultWorkers, "number of words looked up at the same time")
+	meaning := flag.String("meaning", "", "find words that match a description, using the Datamuse API")
+	defineTop := flag.Bool("define-top", false, "with --meaning, also look up the best matching word")
+	rhymes := flag.String("rhymes", "", "list words that rhyme with a word, using the Datamuse API")
+	grepTerm := flag.String("grep", "", "find saved words whose definitions contain a term")
+	quiz := flag.Bool("quiz", false, "guess saved words from their definitions")
+	questions := flag.Int("questions", defaultQuizQuestions, "number of --quiz questions")
+	star := flag.String("star", "", "add a word to your favorites")
+	unstar := flag.String("unstar", "", "remove a word from your favorites")
+	favorites := flag.Bool("favorites", false, "list your favorite words")
+	alias := flag.String("alias", "", "save an alias for a word as alias=word, alias= removes it")
+	aliases := flag.Bool("aliases", false, "list the saved aliases")
+	history := flag.Bool("history", false, "list your most recent lookups, the last 10 unless a count follows")
+	noHistory := flag.Bool("no-history", false, "do not add lookups to the history log")
+	frequent := flag.Bool("frequent", false, "list the words looked up most often, the top 10 unless a count follows")
+	listWords := flag.Bool("list-words", false, "print saved words one per line in alphabetical order")
+	refresh := flag.Bool("refresh", false, "fetch every saved word from the API again")
+	dryRun := flag.Bool("dry-run", false, "with --refresh, only list the words that would be refreshed")
+	lemmatize := flag.Bool("lemmatize", false, "look up base forms such as run for running when a word is not found")
+	page := flag.Int("page", 1, "page of saved words shown on the welcome screen")
+	pageSize := flag.Int("page-size", defaultPageSize, "number of saved words per page on the welcome screen, 0 shows all")
+	sense := flag.Int("sense", 0, "only show the Nth definition of each part of speech")
+	width := flag.Int("width", autoWidth, "wrap definitions at N characters, 0 disables wrapping, defaults to the terminal width")
+	compare := flag.Bool("compare", false, "show the definitions of two words side by side")
+	check := flag.Bool("check", false, "only report whether words exist, exiting with 2 for unknown words")
+	columnsFlag := flag.String("columns", "", "comma separated table columns to show, in order: "+strings.Join(definitionColumns, ", "))
+	grouped := flag.Bool("grouped", false, "print definitions under a heading per part of speech instead of in a table")
+	syllables := flag.Bool("syllables", false, "show the estimated syllable count and letter count of a word")
+	full := flag.Bool("full", false, "print everything known about a word, grouped by part of speech")
+	anki := flag.Bool("anki", false, "print words as tab separated Anki cards")
+	csvOutput := flag.Bool("csv", false, "print definitions as CSV rows of word, part of speech, definition and example")
+	markdown := flag.Bool("markdown", false, "print a word as a Markdown section")
+	offline := flag.Bool("offline", false, "only look words up in the cache, never call the API")
+	quiet := flag.Bool("quiet", false, "print only the definitions table, without the word and phonetic spelling above it")
+	var showVersion bool
+	flag.BoolVar(&showVersion, "version", false, "print the version of wordef")
+	flag.BoolVar(&showVersion, "v", false, "shorthand for --version")
+	metrics := flag.Bool("metrics", false, "print how many lookups were cache hits and API fetches when done")
+	verbose := flag.Bool("verbose", false, "log each lookup step to stderr")
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+	flag.CommandLine.Usage = func() {}
+
+	err = flag.CommandLine.Parse(os.Args[1:])
+
+	if errors.Is(err, flag.ErrHelp) {
+		handleHelpCommand()
+		os.Exit(0)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Run 'wordef --help' to see every flag")
+		os.Exit(exitError)
+	}
+
+	if showVersion {
+		handleVersionCommand()
+		return
+	}
+
+	if *jsonCompact {
+		*jsonOutput = true
+		jsonIndent = ""
+	}
+
+	if *jsonNormalized {
+		*jsonOutput = true
+	}
+
+	if *verbose {
+		logger = newLogger(slog.LevelDebug)
+	}
+
+	dict.Logger = logger
+
+	configureFromEnv()
+
+	if *timeout < 0 {
+		exitWithError(errors.New("--timeout must not be negative"))
+	}
+
+	if *timeout > 0 {
+		dict.HTTPClient.Timeout = *timeout
+	}
+
+	logger.Debug("Using request timeout", "timeout", dict.HTTPClient.Timeout)
+
+	if *limit < 0 {
+		exitWithError(errors.New("--limit must not be negative"))
+	}
+
+	if *maxMeanings < 0 {
+		exitWithError(errors.New("--max-meanings must not be negative"))
+	}
+
+	if definitionsCount < 0 {
+		exitWithError(errors.New("--definitions-count must not be negative"))
+	}
+
+	if *offline && *noCache {
+		exitWithError(errors.New("--offline and --no-cache cannot be used together"))
+	}
+
+	if *width != autoWidth && (*width < 0 || *width > 0 && *width < minDefinitionWidth) {
+		exitWithError(fmt.Errorf("--width must be 0 or at least %d", minDefinitionWidth))
+	}
+
+	if *page < 1 {
+		exitWithError(errors.New("--page must be at least 1"))
+	}
+
+	if *pageSize < 0 {
+		exitWithError(errors.New("--page-size must not be negative"))
+	}
+
+	if *sense < 0 {
+		exitWithError(errors.New("--sense must not be negative"))
+	}
+
+	var columns []string
+
+	if *columnsFlag != "" {
+		columns, err = parseColumns(*columnsFlag)
+
+		if err != nil {
+			exitWithError(err)
+		}
+	}
+
+	if *questions < 1 {
+		exitWithError(errors.New("--questions must be at least 1"))
+	}
+
+	if *workers < 1 {
+		exitWithError(errors.New("--workers must be at least 1"))
+	}
+
+	err = dict.ValidateLanguage(*lang)
+
+	if err != nil {
+		exitWithError(err)
+	}
+
+	color, err := useColor(*colorMode)
+
+	if err != nil {
+		exitWithError(err)
+	}
+
+	cacheDir, cacheErr := getCacheDir(*cacheDirFlag, config.CacheDir)
+
+	if cacheDir == "" {
+		exitWithError(cacheErr)
+	}
+
+	dataDir, dataErr := dict.ConfigDir()
+
+	if dataDir == "" {
+		exitWithError(dataErr)
+	}
+
+	// Older versions kept the aliases, favorites, counts and history in the
+	// cache directory, which may be one the user chose.
+	if cacheErr == nil && dataErr == nil {
+		moved, err := dict.MigrateData(cacheDir, dataDir)
+
+		if err != nil {
+			logger.Warn("Saved data not moved to the config directory", "from", cacheDir, "to", dataDir, "reason", err)
+		} else if moved > 0 {
+			logger.Info("Moved saved data to the config directory", "from", cacheDir, "to", dataDir, "files", moved)
+		}
+	}
+
+	provider, err := dict.NewProvider(*providerName, os.Getenv("WORDEF_API_KEY"))
 
 	if err != nil {
-		log.Fatalln(err)
+		exitWithError(err)
+	}
+
+	// Other providers keep their words apart, their entries differ from
+	// the ones of dictionaryapi.dev.
+	if provider.Name() != dict.DefaultProvider {
+		cacheDir = filepath.Join(cacheDir, provider.Name())
+	}
+
+	if cacheErr == nil {
+		cacheDir, cacheErr = dict.LanguageCacheDir(cacheDir, *lang)
+	} else {
+		cacheDir = filepath.Join(cacheDir, *lang)
+	}
+
+	logger.Debug("Using cache directory", "dir", cacheDir)
+
+	args := flag.Args()
+
+	opts := searchOptions{
+		SearchOptions: dict.SearchOptions{
+			Lang:       *lang,
+			NoCache:    *noCache,
+			Offline:    *offline,
+			CountsDir:  dataDir,
+			AliasesDir: dataDir,
+			HistoryDir: dataDir,
+			Provider:   provider,
+			Metrics:    &dict.Metrics{},
+		},
+		jsonOutput:       *jsonOutput,
+		limit:            *limit,
+		noExamples:       *noExamples,
+		originOnly:       *originOnly,
+		color:            color,
+		audioOnly:        *audioOnly,
+		play:             *play,
+		allPhonetics:     *allPhonetics,
+		ipaOnly:          *ipaOnly,
+		maxMeanings:      *maxMeanings,
+		partsOfSpeech:    parsePartsOfSpeech(*pos),
+		short:            *short,
+		workers:          *workers,
+		definitionsCount: definitionsCount,
+		quiet:            *quiet,
+		markdown:         *markdown,
+		csv:              *csvOutput,
+		anki:             *anki,
+		full:             *full,
+		syllables:        *syllables,
+		grouped:          *grouped,
+		columns:          columns,
+		check:            *check,
+		lemmatize:        *lemmatize,
+		jsonNormalized:   *jsonNormalized,
+		width:            *width,
+		jsonLines:        *jsonLines,
+		sense:            *sense,
+		page:             *page,
+		pageSize:         *pageSize,
+		dataDir:          dataDir,
+	}
+
+	if *noHistory || os.Getenv("WORDEF_NO_HISTORY") != "" {
+		opts.HistoryDir = ""
+	}
+
+	// Lookups keep working without a usable cache, they just are not saved.
+	if cacheErr != nil {
+		logger.Warn("Cache directory unavailable, words will not be saved", "dir", cacheDir, "reason", cacheErr)
+		opts.NoCache = true
+	} else if !dict.Writable(cacheDir) {
+		logger.Warn("Cache directory is read-only, words will not be saved", "dir", cacheDir)
+		opts.ReadOnly = true
+	}
+
+	dataWritable := dataErr == nil && dict.Writable(dataDir)
+
+	if dataErr != nil {
+		logger.Warn("Config directory unavailable, lookups will not be counted", "dir", dataDir, "reason", dataErr)
+		opts.AliasesDir = ""
+	} else if !dataWritable {
+		logger.Warn("Config directory is read-only, lookups will not be counted", "dir", dataDir)
+	}
+
+	if !dataWritable {
+		opts.CountsDir = ""
+		opts.HistoryDir = ""
 	}
 
-	args := os.Args
+	if len(args) == 2 && args[0] == "completion" {
+		err = handleCompletionCommand(args[1])
+	} else if *meaning != "" {
+		err = handleMeaningCommand(tablewriter.NewWriter(os.Stdout), *meaning, cacheDir, *defineTop, opts)
+	} else if *rhymes != "" {
+		err = handleRhymesCommand(*rhymes)
+	} else if *grepTerm != "" {
+		err = handleGrepCommand(tablewriter.NewWriter(os.Stdout), *grepTerm, cacheDir)
+	} else if *star != "" {
+		err = handleStarCommand(*star, cacheDir, dataDir)
+	} else if *unstar != "" {
+		err = handleUnstarCommand(*unstar, dataDir)
+	} else if *favorites {
+		err = handleFavoritesCommand(dataDir)
+	} else if *alias != "" {
+		err = handleAliasCommand(*alias, dataDir)
+	} else if *aliases {
+		err = handleAliasesCommand(tablewriter.NewWriter(os.Stdout), dataDir)
+	} else if *history {
+		var n int
+
+		n, err = countArg("--history", args)
+
+		if err == nil {
+			err = handleHistoryCommand(tablewriter.NewWriter(os.Stdout), dataDir, n)
+		}
+	} else if *frequent {
+		var n int
 
-	table := tablewriter.NewWriter(os.Stdout)
+		n, err = countArg("--frequent", args)
 
-	if len(args) == 2 {
-		word := capitalizeString(args[1])
-		handleSearchCommand(table, word, cacheDir)
+		if err == nil {
+			err = handleFrequentCommand(tablewriter.NewWriter(os.Stdout), dataDir, n)
+		}
+	} else if *listWords {
+		err = handleListWordsCommand(cacheDir)
+	} else if *stats {
+		err = handleStatsCommand(tablewriter.NewWriter(os.Stdout), cacheDir)
+	} else if *exportFile != "" {
+		err = handleExportCommand(*exportFile, cacheDir)
+	} else if *importFile != "" {
+		err = handleImportCommand(*importFile, cacheDir, *overwrite)
+	} else if *refresh {
+		err = handleRefreshCommand(cacheDir, *dryRun, opts)
+	} else if *clearAll {
+		err = handleClearCommand(cacheDir, *force)
+	} else if *wotd {
+		err = handleWordOfTheDayCommand(cacheDir, opts)
+	} else if *random {
+		err = handleRandomCommand(cacheDir, opts)
+	} else if *quiz {
+		err = handleQuizCommand(cacheDir, *questions, opts)
+	} else if *fromStdin {
+		err = handleStdinCommand(cacheDir, opts)
+	} else if *interactive {
+		err = handleInteractiveCommand(cacheDir, opts)
+	} else if *openWord != "" {
+		err = handleOpenCommand(*openWord, cacheDir, opts)
+	} else if *deleteWord != "" {
+		err = handleDeleteCommand(*deleteWord, cacheDir)
+	} else if *synonyms || *antonyms {
+		if len(args) != 1 {
+			err = errors.New("--synonyms and --antonyms expect exactly one word")
+		} else {
+			err = handleRelatedWordsCommand(args[0], cacheDir, opts, *antonyms)
+		}
+	} else if *compare {
+		err = handleCompareCommand(tablewriter.NewWriter(os.Stdout), args, cacheDir, opts)
+	} else if len(args) > 0 {
+		err = handleSearchCommands(args, cacheDir, opts)
+	} else if !isTerminal(os.Stdin) {
+		err = handlePipedCommand(cacheDir, opts)
 	} else {
-		handleWelcomeCommand(table, cacheDir)
+		table := tablewriter.NewWriter(os.Stdout)
+		err = handleWelcomeCommand(table, cacheDir, opts)
+	}
+
+	if *metrics || *verbose {
+		printMetrics(opts.Metrics)
+	}
+
+	if err != nil {
+		exitWithError(err)
 	}
 }
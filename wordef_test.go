@@ -0,0 +1,1187 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/olekukonko/tablewriter"
+
+	"wordef/datamuse"
+	"wordef/dict"
+)
+
+// captureStdout returns what fn prints to stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+
+	defer func() {
+		os.Stdout = stdout
+	}()
+
+	done := make(chan []byte)
+
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- out
+	}()
+
+	fn()
+	w.Close()
+
+	return string(<-done)
+}
+
+func testWordInfo() dict.WordInfo {
+	return dict.WordInfo{
+		Word:     "cat",
+		Phonetic: "/kæt/",
+		Meanings: []dict.Meaning{
+			{
+				PartOfSpeech: "noun",
+				Definitions: []dict.Definition{
+					{Definition: "A small domesticated feline.", Example: "The cat sat on the mat."},
+					{Definition: "Any member of the family Felidae."},
+					{Definition: "A person, especially a man."},
+				},
+			},
+			{
+				PartOfSpeech: "verb",
+				Definitions: []dict.Definition{
+					{Definition: "To hoist the anchor."},
+					{Definition: "To vomit."},
+				},
+			},
+		},
+	}
+}
+
+func renderTable(t *testing.T, wordInfo dict.WordInfo, opts searchOptions) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	out := captureStdout(t, func() {
+		renderDefinitionsTable(tablewriter.NewWriter(&buf), wordInfo, opts)
+	})
+
+	return buf.String() + out
+}
+
+func TestRenderersSkipMeaningsWithoutDefinitions(t *testing.T) {
+	wordInfo := dict.WordInfo{
+		Word: "cat",
+		Meanings: []dict.Meaning{
+			{PartOfSpeech: "noun"},
+			{PartOfSpeech: "verb", Definitions: []dict.Definition{{Definition: "To vomit."}}},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		render func() string
+	}{
+		{"table", func() string { return renderTable(t, wordInfo, searchOptions{width: autoWidth}) }},
+		{"short", func() string { return captureStdout(t, func() { renderShort(wordInfo, false, 0) }) }},
+		{"grouped", func() string {
+			var buf bytes.Buffer
+			renderGrouped(&buf, wordInfo, searchOptions{})
+			return buf.String()
+		}},
+		{"full", func() string {
+			var buf bytes.Buffer
+			renderFull(&buf, wordInfo, false)
+			return buf.String()
+		}},
+		{"markdown", func() string {
+			var buf bytes.Buffer
+			renderMarkdown(&buf, wordInfo)
+			return buf.String()
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := tt.render()
+
+			if !strings.Contains(out, "To vomit.") {
+				t.Errorf("output does not contain the verb definition:\n%s", out)
+			}
+
+			if strings.Contains(out, "noun") {
+				t.Errorf("output shows the noun, which has no definitions:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestHandleDeleteCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		cached []string
+		word   string
+		want   string
+	}{
+		{"cached", []string{"cat"}, "cat", "Removed 'cat' from cache"},
+		{"different case", []string{"cat"}, "Cat", "Removed 'Cat' from cache"},
+		{"not cached", []string{"dog"}, "cat", "'cat' is not in the cache, nothing to remove"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheDir := t.TempDir()
+
+			for _, word := range tt.cached {
+				err := dict.SaveToCache(word, []byte("[]"), cacheDir, false)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var err error
+
+			out := captureStdout(t, func() {
+				err = handleDeleteCommand(tt.word, cacheDir)
+			})
+
+			if err != nil {
+				t.Fatalf("handleDeleteCommand() error = %v", err)
+			}
+
+			if strings.TrimSpace(out) != tt.want {
+				t.Errorf("handleDeleteCommand() printed %q, want %q", out, tt.want)
+			}
+
+			if dict.IsCached(tt.word, cacheDir) {
+				t.Errorf("%s is still cached", tt.word)
+			}
+		})
+	}
+}
+
+func TestRenderDefinitionsTableExamples(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         searchOptions
+		wantExamples bool
+	}{
+		{"wrapped", searchOptions{width: 40}, true},
+		{"unwrapped", searchOptions{width: 0}, true},
+		{"no examples", searchOptions{width: 40, noExamples: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := renderTable(t, testWordInfo(), tt.opts)
+			lines := strings.Split(out, "\n")
+
+			for i, line := range lines {
+				if !strings.Contains(line, "1. A small domesticated feline.") {
+					continue
+				}
+
+				next := ""
+
+				if i+1 < len(lines) {
+					next = lines[i+1]
+				}
+
+				hasExample := strings.HasPrefix(next, `|      |    "The cat sat on the mat."`)
+
+				if hasExample != tt.wantExamples {
+					t.Errorf("line after definition = %q, want example %v:\n%s", next, tt.wantExamples, out)
+				}
+
+				return
+			}
+
+			t.Fatalf("definition not found:\n%s", out)
+		})
+	}
+}
+
+func TestWrapCell(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  string
+	}{
+		{"short", 20, "short"},
+		{"1. a definition that wraps", 15, "1. a definition\nthat wraps"},
+		{"1. def\n   \"an example that wraps\"", 16, "1. def\n   \"an example\n   that wraps\""},
+		{"1. def\n   \"an example that wraps\"", 0, "1. def\n   \"an example that wraps\""},
+	}
+
+	for _, tt := range tests {
+		got := wrapCell(tt.text, tt.width)
+
+		if got != tt.want {
+			t.Errorf("wrapCell(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
+
+// configureForTest runs configureFromEnv with the given environment, putting
+// back the package settings it changes when the test ends.
+func configureForTest(t *testing.T, env map[string]string) {
+	t.Helper()
+
+	timeout, ttl, attempts := dict.HTTPClient.Timeout, dict.CacheTTL, dict.MaxAttempts
+	compress, cacheMax, rate := dict.Compress, dict.CacheMax, dict.Rate
+	historyMax, negativeTtl := dict.HistoryMax, dict.NegativeTTL
+	baseUrl, userAgent, wordsApiUrl := dict.BaseURL, dict.UserAgent, dict.WordsAPIURL
+	datamuseClient, datamuseAgent, datamuseUrl := datamuse.HTTPClient, datamuse.UserAgent, datamuse.BaseURL
+
+	t.Cleanup(func() {
+		dict.HTTPClient.Timeout, dict.CacheTTL, dict.MaxAttempts = timeout, ttl, attempts
+		dict.Compress, dict.CacheMax, dict.Rate = compress, cacheMax, rate
+		dict.HistoryMax, dict.NegativeTTL = historyMax, negativeTtl
+		dict.BaseURL, dict.UserAgent, dict.WordsAPIURL = baseUrl, userAgent, wordsApiUrl
+		datamuse.HTTPClient, datamuse.UserAgent, datamuse.BaseURL = datamuseClient, datamuseAgent, datamuseUrl
+	})
+
+	for name, value := range env {
+		t.Setenv(name, value)
+	}
+
+	configureFromEnv()
+}
+
+func TestConfigureFromEnvAPIURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		suffix string
+	}{
+		{"without trailing slash", ""},
+		{"with trailing slash", "/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				w.Write([]byte(`[{"word":"cat","meanings":[]}]`))
+			}))
+			defer server.Close()
+
+			configureForTest(t, map[string]string{"WORDEF_API_URL": server.URL + tt.suffix})
+
+			_, err := dict.FetchFromAPI(context.Background(), "cat", "en")
+
+			if err != nil {
+				t.Fatalf("FetchFromAPI() error = %v", err)
+			}
+
+			if gotPath != "/en/cat" {
+				t.Errorf("request path = %q, want %q", gotPath, "/en/cat")
+			}
+		})
+	}
+}
+
+// buildBinary builds wordef into a temporary directory.
+func buildBinary(t *testing.T) string {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("builds the binary")
+	}
+
+	bin := filepath.Join(t.TempDir(), "wordef")
+
+	out, err := exec.Command("go", "build", "-o", bin, ".").CombinedOutput()
+
+	if err != nil {
+		t.Fatalf("go build failed: %v\n%s", err, out)
+	}
+
+	return bin
+}
+
+// runBinary runs bin with its config and cache in home, reading stdin, and
+// returns its stdout and exit code.
+func runBinary(t *testing.T, bin, home string, stdin io.Reader, env []string, args ...string) (stdout string, code int) {
+	t.Helper()
+
+	var out bytes.Buffer
+
+	cmd := exec.Command(bin, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = &out
+	cmd.Env = append(os.Environ(),
+		"HOME="+home,
+		"XDG_CONFIG_HOME="+filepath.Join(home, "config"),
+		"XDG_CACHE_HOME="+filepath.Join(home, "cache"),
+		"WORDEF_CACHE_DIR=",
+	)
+	cmd.Env = append(cmd.Env, env...)
+
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+
+	if errors.As(err, &exitErr) {
+		return out.String(), exitErr.ExitCode()
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return out.String(), 0
+}
+
+func TestExitCodes(t *testing.T) {
+	bin := buildBinary(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/en/cat":
+			w.Write([]byte(`[{"word":"cat","meanings":[{"partOfSpeech":"noun","definitions":[{"definition":"A small domesticated feline."}]}]}]`))
+		case "/en/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"title":"No Definitions Found"}`))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"found", []string{"cat"}, 0},
+		{"not found", []string{"qwxz"}, exitNotFound},
+		{"server error", []string{"broken"}, exitError},
+		{"bad flag value", []string{"--limit", "-1", "cat"}, exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := []string{"WORDEF_API_URL=" + server.URL, "WORDEF_RETRIES=1"}
+
+			_, got := runBinary(t, bin, t.TempDir(), nil, env, tt.args...)
+
+			if got != tt.want {
+				t.Errorf("wordef %v exited with %d, want %d", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckLookup(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    []dict.WordInfo
+		err     error
+		wantErr string
+	}{
+		{"found", []dict.WordInfo{testWordInfo()}, nil, ""},
+		{"empty", []dict.WordInfo{}, nil, "No results for 'cat'"},
+		{"no results", nil, &dict.NoResultsError{Word: "cat"}, "No results for 'cat'"},
+		{"not found", nil, &dict.WordNotFoundError{Word: "cat"}, "No definitions found for 'cat'"},
+		{"other error", nil, errors.New("boom"), "Failed to search for word cat: boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := checkLookup("cat", tt.resp, tt.err)
+
+			if tt.wantErr == "" {
+				if err != nil || len(resp) != 1 {
+					t.Errorf("checkLookup() = %v, %v, want the response", resp, err)
+				}
+
+				return
+			}
+
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("checkLookup() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetCacheDir(t *testing.T) {
+	root := t.TempDir()
+
+	flagDir := filepath.Join(root, "flag")
+	envDir := filepath.Join(root, "env")
+	configDir := filepath.Join(root, "config")
+
+	tests := []struct {
+		name      string
+		flagValue string
+		env       string
+		config    string
+		want      string
+	}{
+		{"flag wins", flagDir, envDir, configDir, flagDir},
+		{"environment over config", "", envDir, configDir, envDir},
+		{"config", "", "", configDir, configDir},
+		{"default", "", "", "", filepath.Join(root, "xdg-cache", "wordef")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("WORDEF_CACHE_DIR", tt.env)
+			t.Setenv("XDG_CACHE_HOME", filepath.Join(root, "xdg-cache"))
+			t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "xdg-config"))
+
+			got, err := getCacheDir(tt.flagValue, tt.config)
+
+			if err != nil {
+				t.Fatalf("getCacheDir() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("getCacheDir() = %q, want %q", got, tt.want)
+			}
+
+			info, err := os.Stat(got)
+
+			if err != nil || !info.IsDir() {
+				t.Errorf("cache directory %s was not created: %v", got, err)
+			}
+		})
+	}
+}
+
+func TestDefaultCacheDirMigratesOldWords(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(root, "xdg-cache"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "xdg-config"))
+
+	oldDir := filepath.Join(root, "xdg-config", "wordef")
+	cacheDir := filepath.Join(root, "xdg-cache", "wordef")
+
+	for name, content := range map[string]string{
+		"Cat.json":       `[{"word":"cat","meanings":[]}]`,
+		"aliases.json":   "{}",
+		"en/dog.json":    "[]",
+		"fr/chat.json":   "[]",
+		"favorites.json": "[]",
+	} {
+		path := filepath.Join(oldDir, name)
+
+		err := os.MkdirAll(filepath.Dir(path), dict.DirPerm)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.WriteFile(path, []byte(content), dict.FilePerm)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := getCacheDir("", "")
+
+	if err != nil {
+		t.Fatalf("getCacheDir() error = %v", err)
+	}
+
+	if got != cacheDir {
+		t.Fatalf("getCacheDir() = %q, want %q", got, cacheDir)
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{filepath.Join(cacheDir, "en", "cat.json"), true},
+		{filepath.Join(cacheDir, "en", "dog.json"), true},
+		{filepath.Join(cacheDir, "fr", "chat.json"), true},
+		{filepath.Join(oldDir, "Cat.json"), false},
+		{filepath.Join(oldDir, "aliases.json"), true},
+		{filepath.Join(oldDir, "favorites.json"), true},
+		{filepath.Join(cacheDir, "aliases.json"), false},
+	}
+
+	for _, tt := range tests {
+		_, err := os.Stat(tt.path)
+
+		if exists := err == nil; exists != tt.want {
+			t.Errorf("%s exists = %v, want %v", tt.path, exists, tt.want)
+		}
+	}
+}
+
+// cacheWords saves a minimal entry for each word in a new cache directory.
+func cacheWords(t *testing.T, words ...string) string {
+	t.Helper()
+
+	cacheDir := t.TempDir()
+
+	for _, word := range words {
+		err := dict.SaveToCache(word, []byte(`[{"word":"`+word+`","meanings":[]}]`), cacheDir, false)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return cacheDir
+}
+
+func TestHandleListWordsCommand(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+		want  string
+	}{
+		{"empty", nil, ""},
+		{"sorted", []string{"zebra", "apple", "mango", "banana"}, "apple\nbanana\nmango\nzebra\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheDir := cacheWords(t, tt.words...)
+
+			var err error
+
+			out := captureStdout(t, func() {
+				err = handleListWordsCommand(cacheDir)
+			})
+
+			if err != nil {
+				t.Fatalf("handleListWordsCommand() error = %v", err)
+			}
+
+			if out != tt.want {
+				t.Errorf("handleListWordsCommand() printed %q, want %q", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderCachedWordsTableSorted(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+		want  []string
+	}{
+		{"unsorted", []string{"zebra", "apple", "mango"}, []string{"Apple", "Mango", "Zebra"}},
+		{"mixed case", []string{"banana", "Apple", "cherry", "apricot"}, []string{"Apple", "Apricot", "Banana", "Cherry"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			err := renderCachedWordsTable(tablewriter.NewWriter(&buf), tt.words, nil, 1, 0)
+
+			if err != nil {
+				t.Fatalf("renderCachedWordsTable() error = %v", err)
+			}
+
+			var got []string
+
+			for _, line := range strings.Split(buf.String(), "\n") {
+				cell := strings.Trim(line, "| ")
+
+				if cell != "" && !strings.HasPrefix(cell, "+") && cell != "SAVED WORDS" {
+					got = append(got, cell)
+				}
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("rows = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectSynonymsAndAntonyms(t *testing.T) {
+	wordInfo := dict.WordInfo{
+		Word: "big",
+		Meanings: []dict.Meaning{
+			{
+				PartOfSpeech: "adjective",
+				Synonyms:     []any{"large", "huge", "Large"},
+				Antonyms:     []any{"small"},
+				Definitions: []dict.Definition{
+					{Definition: "Of great size.", Synonyms: []any{"huge", " vast ", 42, nil}, Antonyms: []any{"little", "small"}},
+					{Definition: "Important.", Synonyms: []any{"", "major"}},
+				},
+			},
+			{
+				PartOfSpeech: "adverb",
+				Synonyms:     []any{"greatly", "Huge"},
+				Antonyms:     []any{"Little"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"synonyms", collectSynonyms(wordInfo), []string{"greatly", "huge", "large", "major", "vast"}},
+		{"antonyms", collectAntonyms(wordInfo), []string{"little", "small"}},
+		{"none", collectSynonyms(dict.WordInfo{Word: "cat"}), []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !slices.Equal(tt.got, tt.want) {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserAgentHeader(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		want string
+	}{
+		{"default", "", "wordef/" + version},
+		{"WORDEF_USER_AGENT", "my-script/2.0", "my-script/2.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("User-Agent")
+				w.Write([]byte(`[{"word":"cat","meanings":[]}]`))
+			}))
+			defer server.Close()
+
+			configureForTest(t, map[string]string{"WORDEF_API_URL": server.URL, "WORDEF_USER_AGENT": tt.env})
+
+			_, err := dict.FetchFromAPI(context.Background(), "cat", "en")
+
+			if err != nil {
+				t.Fatalf("FetchFromAPI() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("User-Agent = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadWords(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"", nil},
+		{"cat\n", []string{"cat"}},
+		{"cat", []string{"cat"}},
+		{"  cat \r\n\n dog\n", []string{"cat", "dog"}},
+		{"ice cream\n", []string{"ice cream"}},
+	}
+
+	for _, tt := range tests {
+		got, err := readWords(strings.NewReader(tt.input))
+
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("readWords(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestPipedWord(t *testing.T) {
+	bin := buildBinary(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/en/cat" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Write([]byte(`[{"word":"cat","meanings":[{"partOfSpeech":"noun","definitions":[{"definition":"A small domesticated feline."}]}]}]`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		stdin    string
+		want     string
+		wantCode int
+	}{
+		{"one word", "cat\n", "A small domesticated feline.", 0},
+		{"here-string without newline", "cat", "A small domesticated feline.", 0},
+		{"nothing piped", "", "Commands:", 0},
+		{"several words", "cat\ndog\n", "", exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := []string{"WORDEF_API_URL=" + server.URL}
+
+			out, code := runBinary(t, bin, t.TempDir(), strings.NewReader(tt.stdin), env)
+
+			if code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d", code, tt.wantCode)
+			}
+
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("output = %q, want it to contain %q", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestSenseIndex(t *testing.T) {
+	tests := []struct {
+		count, sense int
+		wantIndex    int
+		wantOk       bool
+	}{
+		{3, 1, 0, true},
+		{3, 3, 2, true},
+		{3, 4, 2, false},
+		{1, 9, 0, false},
+	}
+
+	for _, tt := range tests {
+		index, ok := senseIndex(tt.count, tt.sense)
+
+		if index != tt.wantIndex || ok != tt.wantOk {
+			t.Errorf("senseIndex(%d, %d) = %d, %v, want %d, %v", tt.count, tt.sense, index, ok, tt.wantIndex, tt.wantOk)
+		}
+	}
+}
+
+func TestRenderDefinitionsTableSense(t *testing.T) {
+	tests := []struct {
+		name     string
+		sense    int
+		want     []string
+		notWant  []string
+		wantNote string
+	}{
+		{
+			name:    "in range",
+			sense:   2,
+			want:    []string{"2. Any member of the family Felidae.", "2. To vomit."},
+			notWant: []string{"1. ", "3. "},
+		},
+		{
+			name:     "clamped for one part of speech",
+			sense:    3,
+			want:     []string{"3. A person, especially a man.", "2. To vomit."},
+			notWant:  []string{"1. "},
+			wantNote: "(verb has only 2, showing the last definition instead of sense 3)",
+		},
+		{
+			name:     "clamped for every part of speech",
+			sense:    9,
+			want:     []string{"3. A person, especially a man.", "2. To vomit."},
+			notWant:  []string{"1. "},
+			wantNote: "(noun has only 3, verb has only 2, showing the last definition instead of sense 9)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := renderTable(t, testWordInfo(), searchOptions{sense: tt.sense, columns: defaultColumns})
+
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("table does not contain %q:\n%s", want, out)
+				}
+			}
+
+			for _, notWant := range tt.notWant {
+				if strings.Contains(out, notWant) {
+					t.Errorf("table contains %q:\n%s", notWant, out)
+				}
+			}
+
+			hasNote := strings.Contains(out, "showing the last definition")
+
+			if tt.wantNote == "" && hasNote || tt.wantNote != "" && !strings.Contains(out, tt.wantNote) {
+				t.Errorf("table note, want %q:\n%s", tt.wantNote, out)
+			}
+		})
+	}
+}
+
+func TestDisplayWord(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"", ""},
+		{"cat", "Cat"},
+		{"ice cream", "Ice cream"},
+		{"élan", "Élan"},
+		{"iPhone", "iPhone"},
+		{"NASA", "NASA"},
+		{"McDonald", "McDonald"},
+	}
+
+	for _, tt := range tests {
+		if got := displayWord(tt.word); got != tt.want {
+			t.Errorf("displayWord(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestHandleSearchResultShowsApiWord(t *testing.T) {
+	tests := []struct {
+		arg     string
+		apiWord string
+		want    string
+	}{
+		{"IPHONE", "iPhone", "iPhone"},
+		{"nasa", "NASA", "NASA"},
+		{"CAT", "cat", "Cat"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			wordInfo := testWordInfo()
+			wordInfo.Word = tt.apiWord
+
+			var buf bytes.Buffer
+			var err error
+
+			out := captureStdout(t, func() {
+				err = handleSearchResult(tablewriter.NewWriter(&buf), tt.arg, []dict.WordInfo{wordInfo}, nil, t.TempDir(), searchOptions{columns: defaultColumns})
+			})
+
+			if err != nil {
+				t.Fatalf("handleSearchResult() error = %v", err)
+			}
+
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("output does not show %q:\n%s", tt.want, out)
+			}
+
+			if tt.arg != tt.want && strings.Contains(out+buf.String(), tt.arg) {
+				t.Errorf("output shows the argument %q:\n%s", tt.arg, out)
+			}
+		})
+	}
+}
+
+func TestCountArg(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    int
+		wantErr bool
+	}{
+		{nil, 0, false},
+		{[]string{"20"}, 20, false},
+		{[]string{"1"}, 1, false},
+		{[]string{"0"}, 0, true},
+		{[]string{"-3"}, 0, true},
+		{[]string{"ten"}, 0, true},
+		{[]string{"1", "2"}, 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := countArg("--history", tt.args)
+
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("countArg(%q) = %d, %v, want %d, error %v", tt.args, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestHistoryAndFrequentCounts(t *testing.T) {
+	dir := t.TempDir()
+
+	for i := range 15 {
+		word := fmt.Sprintf("word%02d", i)
+
+		for range i + 1 {
+			err := dict.RecordHistory(dir, word)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = dict.RecordLookup(dir, word)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name     string
+		frequent bool
+		n        int
+		wantRows int
+		wantTop  string
+	}{
+		{"history default", false, 0, defaultHistoryCount, "word14"},
+		{"history count", false, 3, 3, "word14"},
+		{"frequent default", true, 0, defaultFrequentCount, "word14"},
+		{"frequent count", true, 12, 12, "word14"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			var err error
+
+			if tt.frequent {
+				err = handleFrequentCommand(tablewriter.NewWriter(&buf), dir, tt.n)
+			} else {
+				err = handleHistoryCommand(tablewriter.NewWriter(&buf), dir, tt.n)
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rows := strings.Count(buf.String(), "| word")
+
+			if rows != tt.wantRows {
+				t.Errorf("%d rows, want %d:\n%s", rows, tt.wantRows, buf.String())
+			}
+
+			if !strings.Contains(buf.String(), tt.wantTop) {
+				t.Errorf("table does not show %s:\n%s", tt.wantTop, buf.String())
+			}
+		})
+	}
+}
+
+func TestUnusableCacheDir(t *testing.T) {
+	bin := buildBinary(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"word":"cat","meanings":[{"partOfSpeech":"noun","definitions":[{"definition":"A small domesticated feline."}]}]}]`))
+	}))
+	defer server.Close()
+
+	home := t.TempDir()
+
+	// Nothing can be created below a file, even when running as root.
+	env := []string{"WORDEF_API_URL=" + server.URL, "WORDEF_CACHE_DIR=/dev/null/wordef"}
+
+	for _, args := range [][]string{{"cat"}, {"--no-cache", "cat"}} {
+		out, code := runBinary(t, bin, home, nil, env, args...)
+
+		if code != 0 || !strings.Contains(out, "A small domesticated feline.") {
+			t.Fatalf("wordef %v exited with %d and printed:\n%s", args, code, out)
+		}
+	}
+
+	out, code := runBinary(t, bin, home, nil, env, "--frequent")
+
+	if code != 0 || !regexp.MustCompile(`\| +cat +\| +2 +\|`).MatchString(out) {
+		t.Errorf("wordef --frequent exited with %d and printed:\n%s\nwant cat counted twice", code, out)
+	}
+}
+
+func TestLimitMeanings(t *testing.T) {
+	meanings := testWordInfo().Meanings
+
+	tests := []struct {
+		max         int
+		wantKept    int
+		wantOmitted int
+	}{
+		{0, 2, 0},
+		{-1, 2, 0},
+		{1, 1, 1},
+		{2, 2, 0},
+		{5, 2, 0},
+	}
+
+	for _, tt := range tests {
+		kept, omitted := limitMeanings(meanings, tt.max)
+
+		if len(kept) != tt.wantKept || omitted != tt.wantOmitted {
+			t.Errorf("limitMeanings(%d) kept %d and omitted %d, want %d and %d", tt.max, len(kept), omitted, tt.wantKept, tt.wantOmitted)
+		}
+
+		if len(kept) > 0 && kept[0].PartOfSpeech != "noun" {
+			t.Errorf("limitMeanings(%d) kept %q first, want noun", tt.max, kept[0].PartOfSpeech)
+		}
+	}
+}
+
+func TestHandleSearchResultMaxMeanings(t *testing.T) {
+	const note = "(+1 more parts of speech, use --max-meanings 0 to see all)"
+
+	tests := []struct {
+		name       string
+		opts       searchOptions
+		wantVerb   bool
+		wantNote   bool
+		wantSecond bool
+	}{
+		{"unlimited", searchOptions{columns: defaultColumns}, true, false, true},
+		{"capped", searchOptions{columns: defaultColumns, maxMeanings: 1}, false, true, true},
+		{"capped grouped", searchOptions{columns: defaultColumns, maxMeanings: 1, grouped: true}, false, true, true},
+		{"high cap", searchOptions{columns: defaultColumns, maxMeanings: 2}, true, false, true},
+		{"independent of definition limit", searchOptions{columns: defaultColumns, maxMeanings: 1, limit: 1}, false, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			var err error
+
+			out := captureStdout(t, func() {
+				err = handleSearchResult(tablewriter.NewWriter(&buf), "cat", []dict.WordInfo{testWordInfo()}, nil, t.TempDir(), tt.opts)
+			})
+
+			if err != nil {
+				t.Fatalf("handleSearchResult() error = %v", err)
+			}
+
+			all := out + buf.String()
+
+			if got := strings.Contains(all, "To hoist the anchor."); got != tt.wantVerb {
+				t.Errorf("verb shown = %v, want %v:\n%s", got, tt.wantVerb, all)
+			}
+
+			if got := strings.Contains(out, note); got != tt.wantNote {
+				t.Errorf("note shown = %v, want %v:\n%s", got, tt.wantNote, out)
+			}
+
+			if got := strings.Contains(all, "Any member of the family Felidae."); got != tt.wantSecond {
+				t.Errorf("second noun definition shown = %v, want %v:\n%s", got, tt.wantSecond, all)
+			}
+		})
+	}
+}
+
+func TestChosenCacheDirKeepsOtherFiles(t *testing.T) {
+	bin := buildBinary(t)
+	home := t.TempDir()
+	project := t.TempDir()
+
+	files := map[string]string{
+		"package.json":  `{"name":"proj"}`,
+		"tsconfig.json": "{}",
+		"cat.json":      `[{"word":"cat","meanings":[{"partOfSpeech":"noun","definitions":[{"definition":"A small domesticated feline."}]}]}]`,
+	}
+
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(project, name), []byte(content), dict.FilePerm)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runBinary(t, bin, home, nil, nil, "--cache-dir", project, "--offline", "cat")
+
+	for name := range files {
+		_, err := os.Stat(filepath.Join(project, name))
+
+		if err != nil {
+			t.Errorf("%s was moved out of the cache directory: %v", name, err)
+		}
+	}
+}
+
+func TestChosenCacheDirDataMovesToConfigDir(t *testing.T) {
+	bin := buildBinary(t)
+
+	tests := []struct {
+		name string
+		args []string
+		env  func(dir string) []string
+	}{
+		{"flag", []string{"--cache-dir"}, func(string) []string { return nil }},
+		{"environment", nil, func(dir string) []string { return []string{"WORDEF_CACHE_DIR=" + dir} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			cacheDir := t.TempDir()
+
+			files := map[string]string{
+				"aliases.json":   `{"kitty":"cat"}`,
+				"favorites.json": `["cat"]`,
+				"counts.json":    `{"cat":3}`,
+			}
+
+			for name, content := range files {
+				err := os.WriteFile(filepath.Join(cacheDir, name), []byte(content), dict.FilePerm)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			args := tt.args
+
+			if args != nil {
+				args = append(args, cacheDir)
+			}
+
+			checks := []struct {
+				command string
+				want    *regexp.Regexp
+			}{
+				{"--favorites", regexp.MustCompile(`(?m)^cat$`)},
+				{"--aliases", regexp.MustCompile(`kitty +\| +cat`)},
+				{"--frequent", regexp.MustCompile(`cat +\| +3`)},
+			}
+
+			for _, check := range checks {
+				out, code := runBinary(t, bin, home, nil, tt.env(cacheDir), append(slices.Clone(args), check.command)...)
+
+				if code != 0 || !check.want.MatchString(out) {
+					t.Errorf("wordef %s exited with %d and printed:\n%s", check.command, code, out)
+				}
+			}
+
+			for name := range files {
+				_, err := os.Stat(filepath.Join(home, "config", "wordef", name))
+
+				if err != nil {
+					t.Errorf("%s not moved to the config directory: %v", name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestPrintUsageHistory(t *testing.T) {
+	out := captureStdout(t, printUsage)
+
+	for _, want := range []string{"--history [N]", "WORDEF_HISTORY_MAX", "WORDEF_NO_HISTORY"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("usage does not mention %s:\n%s", want, out)
+		}
+	}
+}